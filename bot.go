@@ -1,53 +1,176 @@
 package gotbot
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/roskee/gotbot/entity"
 	"github.com/roskee/gotbot/envelop"
 	"github.com/roskee/gotbot/router"
 )
 
-var apiString = "https://api.telegram.org/bot%s/%s"
-var apiFileString = "https://api.telegram.org/file/bot%s/%s"
+// DefaultAPIEndpoint is the endpoint of the public telegram bot api server.
+const DefaultAPIEndpoint = "https://api.telegram.org"
+
+// apiString is the url of a method or, under BotOptions.FileEndpoint, of a file.
+var apiString = "%s/bot%s/%s"
 
 // Bot is a gateway to manage a telegram bot
 type Bot interface {
 
 	// SendRawRequest sends a request to the telegram server and returns the result part of the response as a serialized json body
 	SendRawRequest(httpMethod, function string, getBody func() (io.Reader, BodyOptions, error), setReq func(req *http.Request) error) ([]byte, error)
+	// SendRawRequestContext is the same as SendRawRequest but carries ctx to the request.
+	SendRawRequestContext(ctx context.Context, httpMethod, function string, getBody func() (io.Reader, BodyOptions, error), setReq func(req *http.Request) error) ([]byte, error)
 
-	// RegisterMethod registers a new bot command with its name, description and implementation to the telegram server
+	// RegisterMethod registers a new bot command with its name, description and implementation.
+	// The command is only registered locally, call SyncCommands once all commands are registered
+	// to publish them to the telegram server.
+	// Registering a name again replaces the command.
 	RegisterMethod(name, description string, function func(update entity.Update)) error
+	// UnregisterMethod removes the command name registered with RegisterMethod.
+	// It is removed from the telegram server on the next SyncCommands.
+	// It reports whether the command was registered.
+	UnregisterMethod(name string) bool
+	// SyncCommands publishes the registered commands to the telegram server.
+	// The commands on the server are fetched first and only changed if they differ from the registered ones.
+	SyncCommands() error
+	// SyncCommandsContext is the same as SyncCommands but carries ctx to the request.
+	SyncCommandsContext(ctx context.Context) error
 
 	// SendMessage is the implementation of the builtin sendMessage function of the bot.
 	// It sends the given message to the sender user
 	SendMessage(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendMessageContext is the same as SendMessage but carries ctx to the request.
+	SendMessageContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 
 	// SendMessageAny can be used to send any kind of message manually.
 	// All other Send* messages use this method internally.
 	SendMessageAny(msgType MessageType, message entity.MessageEnvelop, response any, attachedFiles ...entity.FileEnvelop) error
+	// SendMessageAnyContext is the same as SendMessageAny but carries ctx to the request.
+	SendMessageAnyContext(ctx context.Context, msgType MessageType, message entity.MessageEnvelop, response any, attachedFiles ...entity.FileEnvelop) error
+
+	// SendLongMessage sends a text message longer than the limit of the telegram server as several messages.
+	// The text is split at paragraph, line or word boundaries and formatted by its entities or parse mode,
+	// whose formatting is kept across the messages. Only the last message gets the reply markup.
+	// Text in the legacy Markdown parse mode can't be split and returns an error if it is too long;
+	// use MarkdownV2 or HTML instead.
+	// It returns the messages sent, which are the ones sent before the failure in case of an error.
+	SendLongMessage(msg entity.MessageEnvelop) ([]entity.Message, error)
+	// SendLongMessageContext is the same as SendLongMessage but carries ctx to the request.
+	SendLongMessageContext(ctx context.Context, msg entity.MessageEnvelop) ([]entity.Message, error)
+	// SendLongMessageAny is the same as SendLongMessage but sends any kind of message.
+	// The caption of a media message is cut to its limit and the rest of it is sent as text messages after it.
+	SendLongMessageAny(msgType MessageType, msg entity.MessageEnvelop) ([]entity.Message, error)
+	// SendLongMessageAnyContext is the same as SendLongMessageAny but carries ctx to the request.
+	SendLongMessageAnyContext(ctx context.Context, msgType MessageType, msg entity.MessageEnvelop) ([]entity.Message, error)
 
 	// GetMyCommands is the implementation of the builtin getMyCommands function of the bot.
 	// It returns the list of all currently registered commands
 	GetMyCommands() ([]entity.Command, error)
+	// GetMyCommandsContext is the same as GetMyCommands but carries ctx to the request.
+	GetMyCommandsContext(ctx context.Context) ([]entity.Command, error)
+
+	// GetMyCommandsWithOptions is the same as GetMyCommands but returns the commands of a scope and language.
+	GetMyCommandsWithOptions(options envelop.GetMyCommandsEnvelop) ([]entity.Command, error)
+	// GetMyCommandsWithOptionsContext is the same as GetMyCommandsWithOptions but carries ctx to the request.
+	GetMyCommandsWithOptionsContext(ctx context.Context, options envelop.GetMyCommandsEnvelop) ([]entity.Command, error)
 
 	// SetMyCommands is the implementation of the builtin setMyCommands function of the bot.
 	SetMyCommands(commands []entity.Command) error
+	// SetMyCommandsContext is the same as SetMyCommands but carries ctx to the request.
+	SetMyCommandsContext(ctx context.Context, commands []entity.Command) error
+	// SetMyCommandsWithOptions is the same as SetMyCommands but sets the commands of the scope and language of commands.
+	SetMyCommandsWithOptions(commands entity.Commands) error
+	// SetMyCommandsWithOptionsContext is the same as SetMyCommandsWithOptions but carries ctx to the request.
+	SetMyCommandsWithOptionsContext(ctx context.Context, commands entity.Commands) error
+
+	// PublishCommandMenu publishes the commands of every scope and language of menu to the telegram server.
+	// Like SyncCommands, only the scope and language pairs whose commands changed are sent.
+	// Note that SyncCommands publishes the commands registered with RegisterMethod to the default scope
+	// and language, so only one of them should manage that pair.
+	PublishCommandMenu(menu entity.CommandMenu) error
+	// PublishCommandMenuContext is the same as PublishCommandMenu but carries ctx to the request.
+	PublishCommandMenuContext(ctx context.Context, menu entity.CommandMenu) error
+	// GetCommandMenu reads the commands of every pair of scopes and languages back from the telegram server
+	// and returns them as a CommandMenu.
+	GetCommandMenu(scopes []entity.BotCommandScope, languages []string) (entity.CommandMenu, error)
+	// GetCommandMenuContext is the same as GetCommandMenu but carries ctx to the request.
+	GetCommandMenuContext(ctx context.Context, scopes []entity.BotCommandScope, languages []string) (entity.CommandMenu, error)
 
 	// DeleteMyCommands is the implementation of the builtin deleteMyCommands function of the bot.
 	DeleteMyCommands(commandScope envelop.DeleteMyCommandsEnvelop) (bool, error)
+	// DeleteMyCommandsContext is the same as DeleteMyCommands but carries ctx to the request.
+	DeleteMyCommandsContext(ctx context.Context, commandScope envelop.DeleteMyCommandsEnvelop) (bool, error)
+
+	// Identity tells whether commands are addressed to the bot by their @username suffix.
+	// It is shared with the routers created for the bot with router.New.
+	Identity() *router.Identity
 
 	// GetMe is the implementation of the builtin getMe function of the bot
 	GetMe() (entity.User, error)
+	// GetMeContext is the same as GetMe but carries ctx to the request.
+	GetMeContext(ctx context.Context) (entity.User, error)
 
 	// Listen creates a http server to listen for updates as a webhook handler.
 	// It returns on failure only
 	Listen(port int, webhook entity.Webhook, config entity.UpdateConfig) error
+	// ListenContext is the same as Listen but shuts the server down once ctx is done.
+	// Running update handlers are given BotOptions.ShutdownTimeout to complete.
+	//
+	// It returns nil if the server was shut down because ctx is done.
+	ListenContext(ctx context.Context, port int, webhook entity.Webhook, config entity.UpdateConfig) error
+
+	// ListenTLS is the same as Listen but serves https using the certificate and key in certFile and keyFile.
+	// To use a self-signed certificate, upload it by setting webhook.Certificate.
+	//
+	// It returns on failure only
+	ListenTLS(port int, certFile, keyFile string, webhook entity.Webhook, config entity.UpdateConfig) error
+	// ListenTLSContext is the same as ListenTLS but shuts the server down once ctx is done.
+	//
+	// It returns nil if the server was shut down because ctx is done.
+	ListenTLSContext(ctx context.Context, port int, certFile, keyFile string, webhook entity.Webhook, config entity.UpdateConfig) error
+
+	// WebhookHandler returns a http.Handler that handles updates sent by the telegram server to a webhook.
+	// It can be mounted on an existing server. Requests without secretToken in their
+	// `X-Telegram-Bot-Api-Secret-Token` header are rejected.
+	//
+	// The webhook itself has to be registered with SetWebhook.
+	WebhookHandler(secretToken string, config entity.UpdateConfig) http.Handler
+
+	// SetWebhook is the implementation of the builtin setWebhook function of the bot.
+	// It registers the url to which the telegram server sends updates.
+	SetWebhook(webhook entity.Webhook) (bool, error)
+	// SetWebhookContext is the same as SetWebhook but carries ctx to the request.
+	SetWebhookContext(ctx context.Context, webhook entity.Webhook) (bool, error)
+
+	// DeleteWebhook is the implementation of the builtin deleteWebhook function of the bot.
+	// It removes the webhook integration so that updates can be received with GetUpdates.
+	DeleteWebhook(options envelop.DeleteWebhookEnvelop) (bool, error)
+	// DeleteWebhookContext is the same as DeleteWebhook but carries ctx to the request.
+	DeleteWebhookContext(ctx context.Context, options envelop.DeleteWebhookEnvelop) (bool, error)
+
+	// GetWebhookInfo is the implementation of the builtin getWebhookInfo function of the bot.
+	// It returns the current status of the webhook.
+	GetWebhookInfo() (entity.WebhookInfo, error)
+	// GetWebhookInfoContext is the same as GetWebhookInfo but carries ctx to the request.
+	GetWebhookInfoContext(ctx context.Context) (entity.WebhookInfo, error)
+
+	// MonitorWebhook checks the status of the webhook every interval until ctx is done.
+	// An interval that isn't positive is replaced by DefaultWebhookMonitorInterval.
+	// It logs a warning when the number of pending updates grows
+	// and an error when the telegram server reports a new delivery error.
+	MonitorWebhook(ctx context.Context, interval time.Duration)
 
 	// Poll initiates a manual poll to get updates from the telegram server.
 	// instructions on what to do on the updates should be set on config.
@@ -56,21 +179,46 @@ type Bot interface {
 	// It returns on failure only
 	Poll(duration time.Duration, configs entity.UpdateConfig) error
 
+	// PollWithOptions is the same as Poll but uses long polling as configured by options.
+	//
+	// It returns on failure only
+	PollWithOptions(options PollOptions, config entity.UpdateConfig) error
+	// PollContext is the same as PollWithOptions but stops polling once ctx is done.
+	// Running update handlers are given BotOptions.ShutdownTimeout to complete
+	// and the updates that were handled are confirmed to the telegram server before it returns.
+	//
+	// It returns nil if polling was stopped because ctx is done.
+	PollContext(ctx context.Context, options PollOptions, config entity.UpdateConfig) error
+
+	// GetUpdates is the implementation of the builtin getUpdates function of the bot.
+	// It should not be used while a webhook is set or while Poll is running.
+	GetUpdates(options envelop.GetUpdatesEnvelop) ([]entity.Update, error)
+	// GetUpdatesContext is the same as GetUpdates but carries ctx to the request.
+	GetUpdatesContext(ctx context.Context, options envelop.GetUpdatesEnvelop) ([]entity.Update, error)
+
 	// AnswerCallbackQuery send answers to callback queries sent from entity.InlineKeyboardMarkup.
 	AnswerCallbackQuery(options entity.AnswerCallbackQueryEntity) error
+	// AnswerCallbackQueryContext is the same as AnswerCallbackQuery but carries ctx to the request.
+	AnswerCallbackQueryContext(ctx context.Context, options entity.AnswerCallbackQueryEntity) error
 
 	// ForwardMessage is used to forward messages of any kind.
 	// Service messages can't be forwarded.
 	ForwardMessage(msgEnvelop envelop.ForwardMessageEnvelop) (entity.Message, error)
+	// ForwardMessageContext is the same as ForwardMessage but carries ctx to the request.
+	ForwardMessageContext(ctx context.Context, msgEnvelop envelop.ForwardMessageEnvelop) (entity.Message, error)
 
 	// CopyMessage is used to copy messages of any kind.
 	// Service messages and invoice messages can't be copied.
 	// A quiz poll can be copied only if the value of
 	// the field 'correct_option_id' is known to the bot.
 	CopyMessage(msgEnvelop envelop.CopyMessageEnvelop) (int64, error)
+	// CopyMessageContext is the same as CopyMessage but carries ctx to the request.
+	CopyMessageContext(ctx context.Context, msgEnvelop envelop.CopyMessageEnvelop) (int64, error)
 
 	// SendPhoto is used to send photos.
 	SendPhoto(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendPhotoContext is the same as SendPhoto but carries ctx to the request.
+	SendPhotoContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 
 	// SendAudio is used to send audio files.
 	// For telegrm to show the audio in the music player,
@@ -78,6 +226,8 @@ type Bot interface {
 	//
 	// Note: bots can only send audio files up to 50 MB in size.
 	SendAudio(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendAudioContext is the same as SendAudio but carries ctx to the request.
+	SendAudioContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 
 	// SendVideo is used to send video files.
 	// Only MPEG4 videos are supported.
@@ -85,64 +235,114 @@ type Bot interface {
 	//
 	// Note: bots can only send video files up to 50 MB in size.
 	SendVideo(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendVideoContext is the same as SendVideo but carries ctx to the request.
+	SendVideoContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 
 	// SendLocation is used to send location
 	SendLocation(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendLocationContext is the same as SendLocation but carries ctx to the request.
+	SendLocationContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 	// SendDocument is used to send general files.
 	//
 	// Note: bots can only send files of any type up to 50 MB in size.
 	SendDocument(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendDocumentContext is the same as SendDocument but carries ctx to the request.
+	SendDocumentContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 	// SendVoice is used to send audio files.
 	//
 	// Note: bots can only send voice messages up to 50 MB in size.
 
 	SendVoice(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendVoiceContext is the same as SendVoice but carries ctx to the request.
+	SendVoiceContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 
 	// SendMediaGroup is used to send a group of photos, videos, documents or audios as an album.
 	//
 	// Note: Documents and audio files can be only grouped in an album with messages of the same type.
 	SendMediaGroup(msg entity.MessageEnvelop) ([]entity.Message, error)
+	// SendMediaGroupContext is the same as SendMediaGroup but carries ctx to the request.
+	SendMediaGroupContext(ctx context.Context, msg entity.MessageEnvelop) ([]entity.Message, error)
 
 	// SendVideoNote is used to send rounded square mp4 videos of up to 1 minute long.
 	SendVideoNote(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendVideoNoteContext is the same as SendVideoNote but carries ctx to the request.
+	SendVideoNoteContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 
 	// SendContact is used to send phone contacts.
 	SendContact(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendContactContext is the same as SendContact but carries ctx to the request.
+	SendContactContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 	// GetUserProfilePhotos is used to get a list of profile pictures for a user.
 	GetUserProfilePhotos(options envelop.GetUserProfilePhotos) (entity.UserProfilePhotos, error)
+	// GetUserProfilePhotosContext is the same as GetUserProfilePhotos but carries ctx to the request.
+	GetUserProfilePhotosContext(ctx context.Context, options envelop.GetUserProfilePhotos) (entity.UserProfilePhotos, error)
 	// GetFile is used to get basic info about a file and prepare it for downloading.
 	// For the moment, bots can download files of up to 20MB in size.
 	GetFile(options envelop.GetFile) (entity.File, error)
+	// GetFileContext is the same as GetFile but carries ctx to the request.
+	GetFileContext(ctx context.Context, options envelop.GetFile) (entity.File, error)
 	// DownloadFile downloads a file from the telegram server.
 	DownloadFile(file entity.File) ([]byte, error)
+	// DownloadFileContext is the same as DownloadFile but carries ctx to the request.
+	DownloadFileContext(ctx context.Context, file entity.File) ([]byte, error)
 	// SendPoll is used to send a native poll.
 	SendPoll(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendPollContext is the same as SendPoll but carries ctx to the request.
+	SendPollContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 	// SendChatAction is used to send a chat action.
 	SendChatAction(msg entity.MessageEnvelop) (bool, error)
+	// SendChatActionContext is the same as SendChatAction but carries ctx to the request.
+	SendChatActionContext(ctx context.Context, msg entity.MessageEnvelop) (bool, error)
 	// SendAnimation is used to send animation files.
 	// For the moment, bots can send animation files of up to 50 MB in size.
 	SendAnimation(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendAnimationContext is the same as SendAnimation but carries ctx to the request.
+	SendAnimationContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 	// SendDice is used to send an animated emoji that will display a random value.
 	SendDice(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendDiceContext is the same as SendDice but carries ctx to the request.
+	SendDiceContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
 	// SendVenue is used to send information about a venue.
 	SendVenue(msg entity.MessageEnvelop) (entity.Message, error)
+	// SendVenueContext is the same as SendVenue but carries ctx to the request.
+	SendVenueContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error)
+	// GetChatMember is used to get information about a member of a chat.
+	GetChatMember(options envelop.GetChatMember) (entity.ChatMember, error)
+	// GetChatMemberContext is the same as GetChatMember but carries ctx to the request.
+	GetChatMemberContext(ctx context.Context, options envelop.GetChatMember) (entity.ChatMember, error)
 	// SetChatAdministratorCustomTitle is used to set a custom title for an administrator
 	// in a supergroup promoted by the bot.
 	SetChatAdministratorCustomTitle(title envelop.SetChatAdministratorCustomTitle) (bool, error)
+	// SetChatAdministratorCustomTitleContext is the same as SetChatAdministratorCustomTitle but carries ctx to the request.
+	SetChatAdministratorCustomTitleContext(ctx context.Context, title envelop.SetChatAdministratorCustomTitle) (bool, error)
 	// EditMessageText is used to edit text and game messages.
 	EditMessageText(msg envelop.EditMessageTextEnvelop) (entity.Message, error)
+	// EditMessageTextContext is the same as EditMessageText but carries ctx to the request.
+	EditMessageTextContext(ctx context.Context, msg envelop.EditMessageTextEnvelop) (entity.Message, error)
 	// EditMessageCaption is used to edit captions of messages.
 	EditMessageCaption(msg envelop.EditMessageCaptionEnvelop) (entity.Message, error)
+	// EditMessageCaptionContext is the same as EditMessageCaption but carries ctx to the request.
+	EditMessageCaptionContext(ctx context.Context, msg envelop.EditMessageCaptionEnvelop) (entity.Message, error)
 	// EditMessageMedia is used to edit animation, audio, document, photo, or video messages.
 	EditMessageMedia(msg envelop.EditMessageMediaEnvelop) (entity.Message, error)
+	// EditMessageMediaContext is the same as EditMessageMedia but carries ctx to the request.
+	EditMessageMediaContext(ctx context.Context, msg envelop.EditMessageMediaEnvelop) (entity.Message, error)
 	// EditMessageLiveLocation is used to edit live location messages.
 	EditMessageLiveLocation(msg envelop.EditMessageLiveLocationEnvelop) (entity.Message, error)
+	// EditMessageLiveLocationContext is the same as EditMessageLiveLocation but carries ctx to the request.
+	EditMessageLiveLocationContext(ctx context.Context, msg envelop.EditMessageLiveLocationEnvelop) (entity.Message, error)
 	// StopMessageLiveLocation is used to stop updating a live location message before live_period expires.
 	StopMessageLiveLocation(msg envelop.StopMessageLiveLocationEnvelop) (entity.Message, error)
+	// StopMessageLiveLocationContext is the same as StopMessageLiveLocation but carries ctx to the request.
+	StopMessageLiveLocationContext(ctx context.Context, msg envelop.StopMessageLiveLocationEnvelop) (entity.Message, error)
 	// EditMessageReplyMarkup is used to edit only the reply markup of messages.
 	EditMessageReplyMarkup(msg envelop.EditMessageReplyMarkupEnvelop) (entity.Message, error)
+	// EditMessageReplyMarkupContext is the same as EditMessageReplyMarkup but carries ctx to the request.
+	EditMessageReplyMarkupContext(ctx context.Context, msg envelop.EditMessageReplyMarkupEnvelop) (entity.Message, error)
 	// StopPoll is used to stop a poll which was sent by the bot.
 	StopPoll(msg envelop.StopPollEnvelop) (entity.Poll, error)
+	// StopPollContext is the same as StopPoll but carries ctx to the request.
+	StopPollContext(ctx context.Context, msg envelop.StopPollEnvelop) (entity.Poll, error)
 	// DeleteMessage is used to delete a message, including service messages, with the following limitations:
 	// - A message can only be deleted if it was sent less than 48 hours ago.
 	// - Bots can delete outgoing messages in private chats, groups, and supergroups.
@@ -151,9 +351,13 @@ type Bot interface {
 	// - If the bot is an administrator of a group, it can delete any message there.
 	// - If the bot has can_delete_messages permission in a supergroup or a channel, it can delete any message there.
 	DeleteMessage(msg envelop.DeleteMessageEnvelop) (bool, error)
+	// DeleteMessageContext is the same as DeleteMessage but carries ctx to the request.
+	DeleteMessageContext(ctx context.Context, msg envelop.DeleteMessageEnvelop) (bool, error)
 
 	// SendInvoice is used to send invoices.
 	SendInvoice(invoice envelop.SendInvoiceEnvelop) (entity.Message, error)
+	// SendInvoiceContext is the same as SendInvoice but carries ctx to the request.
+	SendInvoiceContext(ctx context.Context, invoice envelop.SendInvoiceEnvelop) (entity.Message, error)
 }
 
 // BotOptions hold the options for the bot
@@ -162,6 +366,32 @@ type BotOptions struct {
 	Logger Logger
 	// Client is the http client to use for sending requests
 	Client http.Client
+	// RetryPolicy controls if and how failed requests are repeated.
+	// Requests are not retried if it is nil.
+	RetryPolicy *RetryPolicy
+	// RateLimiter queues outgoing send and edit requests to stay within the limits of the telegram server.
+	// Requests are not limited if it is nil.
+	RateLimiter *RateLimiter
+	// APIEndpoint is the base url of the bot api server.
+	// It can be set to the url of a self-hosted telegram-bot-api server or a stub server for tests.
+	// Defaults to DefaultAPIEndpoint.
+	APIEndpoint string
+	// FileEndpoint is the base url files are downloaded from.
+	// Defaults to APIEndpoint followed by `/file`.
+	FileEndpoint string
+	// Interceptors wrap every attempt of every request to the telegram server.
+	// The first interceptor is the outermost one.
+	Interceptors []Interceptor
+	// ShutdownTimeout is the time running update handlers are given to complete
+	// when polling or the webhook server is stopped.
+	// Defaults to 10 seconds.
+	ShutdownTimeout time.Duration
+	// Concurrency is the number of updates handled at the same time while polling.
+	// Updates from the same chat (or the same user if there is no chat) are still handled in order.
+	// Updates still queued when polling stops aren't handled and aren't confirmed either,
+	// so the telegram server sends them again the next time the bot polls.
+	// Defaults to 1.
+	Concurrency int
 }
 
 func setDefaultOptions(o BotOptions) BotOptions {
@@ -171,29 +401,90 @@ func setDefaultOptions(o BotOptions) BotOptions {
 		}
 	}
 
+	if o.Concurrency < 1 {
+		o.Concurrency = 1
+	}
+
+	if o.ShutdownTimeout == 0 {
+		o.ShutdownTimeout = 10 * time.Second
+	}
+
+	o.APIEndpoint = strings.TrimSuffix(coalesce(o.APIEndpoint, DefaultAPIEndpoint), "/")
+	o.FileEndpoint = strings.TrimSuffix(coalesce(o.FileEndpoint, o.APIEndpoint+"/file"), "/")
+
 	return o
 }
 
 // bot is in-package implementation of the Bot interface
 type bot struct {
 	apiKey  string
-	methods []router.Handler
 	options BotOptions
+
+	methodsMu sync.RWMutex
+	methods   []router.Handler
+
+	identity *router.Identity
 }
 
 // NewBot returns a new bot with the token apiKey
 func NewBot(apiKey string, options BotOptions) Bot {
-	return &bot{
+	b := &bot{
 		apiKey:  apiKey,
 		options: setDefaultOptions(options),
 	}
+	b.identity = router.NewIdentity(b, RouterLogger(b.options.Logger))
+
+	return b
 }
 
 // SendRawRequest sends a request to the telegram server and returns the result part of the response as a serialized json body
 func (b *bot) SendRawRequest(httpMethod, function string, getBody func() (io.Reader, BodyOptions, error), setReq func(req *http.Request) error) ([]byte, error) {
+	return b.SendRawRequestContext(context.Background(), httpMethod, function, getBody, setReq)
+}
+
+// SendRawRequestContext is the same as SendRawRequest but carries ctx to the request.
+// The request is aborted as soon as ctx is done, in which case the error of ctx is returned.
+//
+// If BotOptions.RetryPolicy is set, failed requests are repeated according to it.
+// getBody is called again before every attempt so it must return a new reader each time.
+func (b *bot) SendRawRequestContext(ctx context.Context, httpMethod, function string, getBody func() (io.Reader, BodyOptions, error), setReq func(req *http.Request) error) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := b.sendRequest(ctx, attempt, httpMethod, function, getBody, setReq)
+		if err == nil {
+			return result, nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		delay, retry := b.options.RetryPolicy.delay(attempt, err)
+		if !retry {
+			return nil, err
+		}
+
+		b.options.Logger.Warn("request failed, retrying", Fields{
+			"function": function,
+			"attempt":  attempt,
+			"delay":    delay.String(),
+			"error":    err.Error(),
+		})
+
+		if waitErr := wait(ctx, delay); waitErr != nil {
+			return nil, waitErr
+		}
+	}
+}
+
+// sendRequest makes a single attempt of a request to the telegram server
+// through the interceptors of the bot.
+func (b *bot) sendRequest(ctx context.Context, attempt int, httpMethod, function string, getBody func() (io.Reader, BodyOptions, error), setReq func(req *http.Request) error) ([]byte, error) {
 	var body io.Reader
 	var options BodyOptions
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if getBody != nil {
 		var err error
 		body, options, err = getBody()
@@ -202,12 +493,39 @@ func (b *bot) SendRawRequest(httpMethod, function string, getBody func() (io.Rea
 		}
 	}
 
-	req, err := http.NewRequest(httpMethod, fmt.Sprintf(apiString, b.apiKey, function), body)
+	call := &Call{
+		HTTPMethod: httpMethod,
+		Function:   function,
+		Options:    options,
+		Header:     http.Header{},
+		Attempt:    attempt,
+	}
+
+	invoke := chainInterceptors(b.options.Interceptors, func(ctx context.Context, call *Call) ([]byte, error) {
+		return b.doRequest(ctx, call, body, setReq)
+	})
+
+	return invoke(ctx, call)
+}
+
+// doRequest sends call to the telegram server
+// and returns the result part of the response.
+func (b *bot) doRequest(ctx context.Context, call *Call, body io.Reader, setReq func(req *http.Request) error) ([]byte, error) {
+	if b.options.RateLimiter != nil && call.Options.ChatID != "" && isRateLimited(call.Function) {
+		if err := b.options.RateLimiter.Wait(ctx, call.Options.ChatID); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, call.HTTPMethod, fmt.Sprintf(apiString, b.options.APIEndpoint, b.apiKey, call.Function), body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", options.ContentType)
+	req.Header.Set("Content-Type", call.Options.ContentType)
+	for key, values := range call.Header {
+		req.Header[key] = values
+	}
 
 	if setReq != nil {
 		err = setReq(req)
@@ -218,8 +536,15 @@ func (b *bot) SendRawRequest(httpMethod, function string, getBody func() (io.Rea
 
 	res, err := b.options.Client.Do(req)
 	if err != nil {
+		// the client wraps the error of a done context in a *url.Error.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, err
 	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
 
 	resBody, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -228,10 +553,18 @@ func (b *bot) SendRawRequest(httpMethod, function string, getBody func() (io.Rea
 	var resData entity.Response
 	err = json.Unmarshal(resBody, &resData)
 	if err != nil {
+		if res.StatusCode >= http.StatusInternalServerError {
+			// proxies in front of the bot api server may answer with a non-json body
+			return nil, &APIError{
+				ErrorCode:   int64(res.StatusCode),
+				Description: http.StatusText(res.StatusCode),
+			}
+		}
+
 		return nil, err
 	}
 	if !resData.OK {
-		return nil, fmt.Errorf("error response: code = %d, description = %s, parameters: %+v", resData.ErrorCode, resData.Description, resData.Parameters)
+		return nil, newAPIError(resData)
 	}
 
 	resultBody, err := json.Marshal(resData.Result)
@@ -241,11 +574,17 @@ func (b *bot) SendRawRequest(httpMethod, function string, getBody func() (io.Rea
 // SendMessageAny can be used to send any kind of message manually.
 // All the default send functions use this internally.
 func (b *bot) SendMessageAny(messageType MessageType, message entity.MessageEnvelop, response any, attachedFiles ...entity.FileEnvelop) error {
+	return b.SendMessageAnyContext(context.Background(), messageType, message, response, attachedFiles...)
+}
+
+// SendMessageAnyContext is the same as SendMessageAny but carries ctx to the request.
+// Building the multipart body is also stopped once ctx is done.
+func (b *bot) SendMessageAnyContext(ctx context.Context, messageType MessageType, message entity.MessageEnvelop, response any, attachedFiles ...entity.FileEnvelop) error {
 	var res []byte
 	var err error
 
-	res, err = b.SendRawRequest(http.MethodPost, string(messageType), func() (io.Reader, BodyOptions, error) {
-		return GetMultipartBody(message, attachedFiles...)
+	res, err = b.SendRawRequestContext(ctx, http.MethodPost, string(messageType), func() (io.Reader, BodyOptions, error) {
+		return GetMultipartBodyContext(ctx, message, attachedFiles...)
 	}, nil)
 	if err != nil {
 		return err
@@ -254,73 +593,57 @@ func (b *bot) SendMessageAny(messageType MessageType, message entity.MessageEnve
 	return json.Unmarshal(res, response)
 }
 
-// RegisterMethod registers a new bot command with its name, description and implementation to the telegram server
+// RegisterMethod registers a new bot command with its name, description and implementation.
+// The command is published to the telegram server with SyncCommands.
 func (b *bot) RegisterMethod(name, description string, function func(update entity.Update)) error {
-	commands, err := b.GetMyCommands()
-	if err != nil {
-		return err
+	if !validCommand(name) {
+		return fmt.Errorf("invalid command name %q: it must be 1-32 lowercase letters, digits or underscores", name)
+	}
+	if description == "" || utf8.RuneCountInString(description) > 256 {
+		return fmt.Errorf("invalid description of command %q: it must be 1-256 characters", name)
 	}
-	commands = append(commands, entity.Command{
-		Command:     name,
+
+	b.methodsMu.Lock()
+	defer b.methodsMu.Unlock()
+
+	method := router.Handler{
+		Name:        name,
 		Description: description,
-	})
-	err = b.SetMyCommands(commands)
-	if err != nil {
-		return err
+		Function:    function,
 	}
-	b.methods = append(b.methods, router.Handler{
-		Name:     name,
-		Function: function,
-	})
+	for i := range b.methods {
+		if b.methods[i].Name == name {
+			b.methods[i] = method
+			return nil
+		}
+	}
+	b.methods = append(b.methods, method)
+
 	return nil
 }
 
+// Identity returns the identity of the bot, which caches its username.
+func (b *bot) Identity() *router.Identity {
+	return b.identity
+}
+
 // executeMethod executes the method specified by name. if the method with the name was not found it simply returns
 func (b *bot) executeMethod(name string, update entity.Update) {
+	b.methodsMu.RLock()
+	var function func(update entity.Update)
 	for _, method := range b.methods {
 		if method.Name == name {
-			method.Function(update)
+			function = method.Function
 			break
 		}
 	}
-}
+	b.methodsMu.RUnlock()
 
-// Listen creates a http server to listen for updates as a webhook handler.
-// It returns on failure only
-func (b *bot) Listen(port int, webhook entity.Webhook, config entity.UpdateConfig) error {
-	_, err := b.SendRawRequest("POST", "setWebhook", func() (io.Reader, BodyOptions, error) {
-		return GetJSONBody(webhook)
-	}, SetApplicationJSON)
-	if err != nil {
-		return err
+	if function != nil {
+		b.safely("command "+name, update, func() {
+			function(update)
+		})
 	}
-
-	return http.ListenAndServe(fmt.Sprintf(":%d", port), http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
-		// check token
-		if webhook.SecretToken != req.Header.Get("X-Telegram-Bot-Api-Secret-Token") {
-			b.options.Logger.Warn("invalid secret token", Fields{
-				"token": req.Header.Get("X-Telegram-Bot-Api-Secret-Token"),
-			})
-			res.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-
-		body, err := io.ReadAll(req.Body)
-		if err != nil {
-			res.WriteHeader(400)
-			return
-		}
-
-		var update entity.Update
-		err = json.Unmarshal(body, &update)
-		if err != nil {
-			res.WriteHeader(500)
-			return
-		}
-
-		b.executeUpdate(update, config)
-		res.WriteHeader(200)
-	}))
 }
 
 // Poll initiates a manual poll to get updates from the telegram server.
@@ -329,100 +652,166 @@ func (b *bot) Listen(port int, webhook entity.Webhook, config entity.UpdateConfi
 //
 // It returns on failure only
 func (b *bot) Poll(duration time.Duration, config entity.UpdateConfig) error {
-	b.options.Logger.Info("deleting webhook if exists", Fields{})
+	return b.PollWithOptions(PollOptions{Interval: duration}, config)
+}
 
-	_, err := b.SendRawRequest(http.MethodPost, "deleteWebhook", nil, nil)
-	if err != nil {
-		b.options.Logger.Error("error while deleting webhook", Fields{
-			"error": err.Error(),
+func (b *bot) executeUpdate(update entity.Update, config entity.UpdateConfig) {
+	if config.OnUpdate != nil {
+		b.safely("OnUpdate", update, func() {
+			config.OnUpdate(update)
 		})
-
-		return err
-	}
-
-	b.options.Logger.Info("Polling started", Fields{
-		"duration": fmt.Sprintf("%fs", duration.Seconds()),
-	})
-
-	var lastUpdate entity.Update
-	for {
-		time.Sleep(duration)
-		updatesJSON, err := b.SendRawRequest(http.MethodGet, fmt.Sprintf("getUpdates?offset=%d", lastUpdate.UpdateID+1), nil, nil)
-		if err != nil {
-			b.options.Logger.Error("Error while polling", Fields{
-				"error": err.Error(),
-			})
-
-			continue
-		}
-		var updates []entity.Update
-		err = json.Unmarshal(updatesJSON, &updates)
-		if err != nil {
-			b.options.Logger.Error("Error while polling", Fields{
-				"error": err.Error(),
-			})
-
-			continue
-		}
-		for _, update := range updates {
-			b.executeUpdate(update, config)
-			lastUpdate = update
-		}
 	}
-}
 
-func (b *bot) executeUpdate(update entity.Update, config entity.UpdateConfig) {
 	if update.Message != nil {
-		if command := update.Message.GetCommand(); command != "" {
-			b.executeMethod(command, update)
+		if command, ok := router.ParseCommand(update.Message); ok && b.identity.IsMine(command) {
+			b.executeMethod(command.Name, update)
 			b.options.Logger.Debug("command executed", Fields{
-				"command": command,
+				"command": command.Name,
 			})
 		}
 		if config.OnMessage != nil {
-			config.OnMessage(*update.Message)
+			b.safely("OnMessage", update, func() {
+				config.OnMessage(*update.Message)
+			})
 		}
 	} else if update.EditedMessage != nil {
 		if config.OnEditedMessage != nil {
-			config.OnEditedMessage(*update.EditedMessage)
+			b.safely("OnEditedMessage", update, func() {
+				config.OnEditedMessage(*update.EditedMessage)
+			})
 		}
 	} else if update.ChannelPost != nil {
 		if config.OnChannelPost != nil {
-			config.OnChannelPost(*update.ChannelPost)
+			b.safely("OnChannelPost", update, func() {
+				config.OnChannelPost(*update.ChannelPost)
+			})
 		}
 	} else if update.EditedChannelPost != nil {
 		if config.OnEditedChannelPost != nil {
-			config.OnEditedChannelPost(*update.EditedChannelPost)
+			b.safely("OnEditedChannelPost", update, func() {
+				config.OnEditedChannelPost(*update.EditedChannelPost)
+			})
 		}
 	} else if update.InlineQuery != nil {
 		if config.OnInlineQuery != nil {
-			config.OnInlineQuery(*update.InlineQuery)
+			b.safely("OnInlineQuery", update, func() {
+				config.OnInlineQuery(*update.InlineQuery)
+			})
 		}
 	} else if update.ChosenInlineResult != nil {
 		if config.OnChosenInlineResult != nil {
-			config.OnChosenInlineResult(*update.ChosenInlineResult)
+			b.safely("OnChosenInlineResult", update, func() {
+				config.OnChosenInlineResult(*update.ChosenInlineResult)
+			})
 		}
 	} else if update.CallbackQuery != nil {
 		if config.OnCallbackQuery != nil {
-			config.OnCallbackQuery(*update.CallbackQuery)
+			b.safely("OnCallbackQuery", update, func() {
+				config.OnCallbackQuery(*update.CallbackQuery)
+			})
+		}
+	} else if update.ShippingQuery != nil {
+		if config.OnShippingQuery != nil {
+			b.safely("OnShippingQuery", update, func() {
+				config.OnShippingQuery(*update.ShippingQuery)
+			})
 		}
-	} else { // TODO: missing update types
+	} else if update.PreCheckoutQuery != nil {
+		if config.OnPreCheckoutQuery != nil {
+			b.safely("OnPreCheckoutQuery", update, func() {
+				config.OnPreCheckoutQuery(*update.PreCheckoutQuery)
+			})
+		}
+	} else if update.Poll != nil {
+		if config.OnPoll != nil {
+			b.safely("OnPoll", update, func() {
+				config.OnPoll(*update.Poll)
+			})
+		}
+	} else if update.PollAnswer != nil {
+		if config.OnPollAnswer != nil {
+			b.safely("OnPollAnswer", update, func() {
+				config.OnPollAnswer(*update.PollAnswer)
+			})
+		}
+	} else if update.MyChatMember != nil {
+		if config.OnMyChatMember != nil {
+			b.safely("OnMyChatMember", update, func() {
+				config.OnMyChatMember(*update.MyChatMember)
+			})
+		}
+	} else if update.ChatMember != nil {
+		if config.OnChatMember != nil {
+			b.safely("OnChatMember", update, func() {
+				config.OnChatMember(*update.ChatMember)
+			})
+		}
+	} else if update.ChatJoinRequest != nil {
+		if config.OnChatJoinRequest != nil {
+			b.safely("OnChatJoinRequest", update, func() {
+				config.OnChatJoinRequest(*update.ChatJoinRequest)
+			})
+		}
+	} else {
 		b.options.Logger.Warn("unknown update", Fields{
 			"update": update,
 		})
 	}
 }
 
+// safely calls the update handler fn and recovers from its panic if there is any.
+func (b *bot) safely(handler string, update entity.Update, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.options.Logger.Error("update handler panicked", Fields{
+				"handler":   handler,
+				"update_id": update.UpdateID,
+				"panic":     fmt.Sprintf("%v", r),
+				"stack":     string(debug.Stack()),
+			})
+		}
+	}()
+
+	fn()
+}
+
 // SetLogger sets the logger of the bot.
 func (b *bot) SetLogger(logger Logger) {
 	b.options.Logger = logger
 }
 
+// DownloadFile downloads a file from the telegram server.
 func (b *bot) DownloadFile(file entity.File) ([]byte, error) {
-	res, err := b.options.Client.Get(fmt.Sprintf(apiFileString, b.apiKey, file.FilePath))
+	return b.DownloadFileContext(context.Background(), file)
+}
+
+// DownloadFileContext is the same as DownloadFile but carries ctx to the request.
+// Reading the file body is also aborted once ctx is done.
+//
+// A self-hosted bot api server running in local mode returns absolute file paths.
+// If BotOptions.APIEndpoint points to such a server, these files are read directly from the local filesystem.
+func (b *bot) DownloadFileContext(ctx context.Context, file entity.File) ([]byte, error) {
+	if b.options.APIEndpoint != DefaultAPIEndpoint && filepath.IsAbs(file.FilePath) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return os.ReadFile(file.FilePath)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(apiString, b.options.FileEndpoint, b.apiKey, file.FilePath), nil)
 	if err != nil {
 		return nil, err
 	}
+
+	res, err := b.options.Client.Do(req)
+	if err != nil {
+		// the client wraps the error of a done context in a *url.Error.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
 	defer func() {
 		err := res.Body.Close()
 		if err != nil {
@@ -0,0 +1,91 @@
+package gotbot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/router"
+)
+
+func TestDownloadFile(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(local, []byte("local"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		_, _ = w.Write([]byte("remote"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		options       BotOptions
+		path          string
+		want          string
+		wantRequested string
+	}{
+		{
+			name:          "relative path",
+			options:       BotOptions{FileEndpoint: server.URL + "/file"},
+			path:          "photos/file_1.jpg",
+			want:          "remote",
+			wantRequested: "/file/bottoken/photos/file_1.jpg",
+		},
+		{
+			name:          "absolute path from the cloud server",
+			options:       BotOptions{FileEndpoint: server.URL + "/file"},
+			path:          local,
+			want:          "remote",
+			wantRequested: "/file/bottoken/" + local,
+		},
+		{
+			name:    "absolute path from a local server",
+			options: BotOptions{APIEndpoint: server.URL},
+			path:    local,
+			want:    "local",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested = ""
+			body, err := NewBot("token", tt.options).DownloadFileContext(context.Background(), entity.File{FilePath: tt.path})
+			if err != nil {
+				t.Fatalf("DownloadFile() error = %v", err)
+			}
+			if string(body) != tt.want || requested != tt.wantRequested {
+				t.Errorf("DownloadFile() = %q from %q; want %q from %q", body, requested, tt.want, tt.wantRequested)
+			}
+		})
+	}
+}
+
+func TestRouterSharesIdentity(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"username":"MyBot"}}`)
+	}))
+	defer server.Close()
+
+	b := NewBot("token", BotOptions{APIEndpoint: server.URL})
+	r := router.New(b)
+
+	command := router.Command{Name: "start", Mention: "MyBot"}
+	if !b.Identity().IsMine(command) || !r.IsMine(command) {
+		t.Error("IsMine() = false for the bot")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("getMe was requested %d times; want once", n)
+	}
+}
@@ -0,0 +1,77 @@
+package gotbot
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"time"
+)
+
+// GenerateSelfSignedCertificate creates a self-signed certificate for host
+// along with its private key, both PEM encoded.
+// host can be an ip address or a host name and the certificate is valid for the given duration.
+//
+// The certificate can be used to serve a webhook with ListenTLS
+// and has to be uploaded to the telegram server with entity.Webhook.Certificate.
+func GenerateSelfSignedCertificate(host string, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notBefore := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			// the telegram server checks the common name against the webhook url
+			CommonName: host,
+		},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(validFor),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	return certPEM, keyPEM, nil
+}
+
+// WriteSelfSignedCertificate is the same as GenerateSelfSignedCertificate
+// but writes the certificate and the private key to certFile and keyFile.
+func WriteSelfSignedCertificate(host string, validFor time.Duration, certFile, keyFile string) error {
+	certPEM, keyPEM, err := GenerateSelfSignedCertificate(host, validFor)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(keyFile, keyPEM, 0o600)
+}
@@ -0,0 +1,146 @@
+package gotbot
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestGenerateSelfSignedCertificate(t *testing.T) {
+	tests := []struct {
+		name         string
+		host         string
+		wantIPs      []net.IP
+		wantDNSNames []string
+	}{
+		{name: "ip address", host: "203.0.113.7", wantIPs: []net.IP{net.ParseIP("203.0.113.7")}},
+		{name: "host name", host: "bot.example.com", wantDNSNames: []string{"bot.example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certPEM, keyPEM, err := GenerateSelfSignedCertificate(tt.host, time.Hour)
+			if err != nil {
+				t.Fatalf("GenerateSelfSignedCertificate() error = %v", err)
+			}
+
+			if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+				t.Errorf("certificate and key don't match: %v", err)
+			}
+
+			cert := parseCertificate(t, certPEM)
+			if cert.Subject.CommonName != tt.host {
+				t.Errorf("common name = %q, want %q", cert.Subject.CommonName, tt.host)
+			}
+			if len(cert.IPAddresses) != len(tt.wantIPs) || (len(tt.wantIPs) != 0 && !cert.IPAddresses[0].Equal(tt.wantIPs[0])) {
+				t.Errorf("ip addresses = %v, want %v", cert.IPAddresses, tt.wantIPs)
+			}
+			if len(cert.DNSNames) != len(tt.wantDNSNames) || (len(tt.wantDNSNames) != 0 && cert.DNSNames[0] != tt.wantDNSNames[0]) {
+				t.Errorf("dns names = %v, want %v", cert.DNSNames, tt.wantDNSNames)
+			}
+			if validFor := cert.NotAfter.Sub(cert.NotBefore); validFor < time.Hour-time.Second || validFor > time.Hour {
+				t.Errorf("certificate is valid for %s, want 1h", validFor)
+			}
+			if err := cert.VerifyHostname(tt.host); err != nil {
+				t.Errorf("VerifyHostname() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestWriteSelfSignedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	if err := WriteSelfSignedCertificate("bot.example.com", time.Hour, certFile, keyFile); err != nil {
+		t.Fatalf("WriteSelfSignedCertificate() error = %v", err)
+	}
+
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		t.Errorf("LoadX509KeyPair() error = %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(keyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("key file permissions = %v, want 0600", perm)
+		}
+	}
+}
+
+func TestSetWebhookUploadsCertificate(t *testing.T) {
+	certPEM, _, err := GenerateSelfSignedCertificate("203.0.113.7", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var url string
+	var uploaded []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("certificate")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(w, `{"ok":false,"error_code":400,"description":%q}`, err.Error())
+			return
+		}
+		defer file.Close()
+
+		url = r.FormValue("url")
+		uploaded, _ = io.ReadAll(file)
+		_, _ = fmt.Fprint(w, `{"ok":true,"result":true}`)
+	}))
+	defer server.Close()
+
+	b := NewBot("token", BotOptions{APIEndpoint: server.URL})
+	ok, err := b.SetWebhookContext(context.Background(), entity.Webhook{
+		URL:         "https://203.0.113.7:8443/webhook",
+		Certificate: &entity.FileEnvelop{Path: "file://" + certFile},
+	})
+	if err != nil || !ok {
+		t.Fatalf("SetWebhookContext() = %v, %v", ok, err)
+	}
+
+	if url != "https://203.0.113.7:8443/webhook" {
+		t.Errorf("url = %q", url)
+	}
+	if !bytes.Equal(uploaded, certPEM) {
+		t.Errorf("uploaded certificate = %q, want %q", uploaded, certPEM)
+	}
+}
+
+func parseCertificate(t *testing.T, certPEM []byte) *x509.Certificate {
+	t.Helper()
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		t.Fatalf("invalid certificate PEM: %q", certPEM)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	return cert
+}
@@ -0,0 +1,174 @@
+package gotbot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/envelop"
+)
+
+// UnregisterMethod removes the command name registered with RegisterMethod.
+func (b *bot) UnregisterMethod(name string) bool {
+	b.methodsMu.Lock()
+	defer b.methodsMu.Unlock()
+
+	for i, method := range b.methods {
+		if method.Name == name {
+			b.methods = append(b.methods[:i], b.methods[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// SyncCommands publishes the registered commands to the telegram server.
+func (b *bot) SyncCommands() error {
+	return b.SyncCommandsContext(context.Background())
+}
+
+// SyncCommandsContext is the same as SyncCommands but carries ctx to the request.
+func (b *bot) SyncCommandsContext(ctx context.Context) error {
+	b.methodsMu.RLock()
+	commands := make([]entity.Command, 0, len(b.methods))
+	for _, method := range b.methods {
+		commands = append(commands, entity.Command{
+			Command:     method.Name,
+			Description: method.Description,
+		})
+	}
+	b.methodsMu.RUnlock()
+
+	return b.syncCommands(ctx, nil, "", commands)
+}
+
+// PublishCommandMenu publishes the commands of every scope and language of menu to the telegram server.
+func (b *bot) PublishCommandMenu(menu entity.CommandMenu) error {
+	return b.PublishCommandMenuContext(context.Background(), menu)
+}
+
+// PublishCommandMenuContext is the same as PublishCommandMenu but carries ctx to the request.
+func (b *bot) PublishCommandMenuContext(ctx context.Context, menu entity.CommandMenu) error {
+	for _, set := range menu.Expand() {
+		if err := b.syncCommands(ctx, set.Scope, set.LanguageCode, set.Commands); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetCommandMenu reads the commands of every pair of scopes and languages back from the telegram server.
+func (b *bot) GetCommandMenu(scopes []entity.BotCommandScope, languages []string) (entity.CommandMenu, error) {
+	return b.GetCommandMenuContext(context.Background(), scopes, languages)
+}
+
+// GetCommandMenuContext is the same as GetCommandMenu but carries ctx to the request.
+func (b *bot) GetCommandMenuContext(ctx context.Context, scopes []entity.BotCommandScope, languages []string) (entity.CommandMenu, error) {
+	// the menu is expanded without commands just to get the scope and language pairs.
+	pairs := entity.CommandMenu{Scopes: scopes, Languages: languages}.Expand()
+
+	for i := range pairs {
+		commands, err := b.getCommands(ctx, envelop.GetMyCommandsEnvelop{
+			Scope:        pairs[i].Scope,
+			LanguageCode: pairs[i].LanguageCode,
+		})
+		if err != nil {
+			return entity.CommandMenu{}, err
+		}
+		pairs[i].Commands = commands
+	}
+
+	return entity.NewCommandMenu(pairs), nil
+}
+
+// syncCommands makes commands the commands of scope and languageCode on the telegram server.
+// Nothing is sent if the server already has the same commands.
+func (b *bot) syncCommands(ctx context.Context, scope *entity.BotCommandScope, languageCode string, commands []entity.Command) error {
+	current, err := b.getCommands(ctx, envelop.GetMyCommandsEnvelop{
+		Scope:        scope,
+		LanguageCode: languageCode,
+	})
+	if err != nil {
+		return err
+	}
+
+	if equalCommands(current, commands) {
+		b.options.Logger.Debug("commands are up to date", Fields{
+			"scope":         scope,
+			"language_code": languageCode,
+		})
+		return nil
+	}
+
+	if len(commands) == 0 {
+		_, err = b.DeleteMyCommandsContext(ctx, envelop.DeleteMyCommandsEnvelop{
+			Scope:        scope,
+			LanguageCode: languageCode,
+		})
+		return err
+	}
+
+	return b.setCommands(ctx, entity.Commands{
+		Commands:     commands,
+		Scope:        scope,
+		LanguageCode: languageCode,
+	})
+}
+
+// getCommands returns the commands of a scope and language on the telegram server.
+func (b *bot) getCommands(ctx context.Context, options envelop.GetMyCommandsEnvelop) ([]entity.Command, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "getMyCommands", func() (io.Reader, BodyOptions, error) {
+		return GetJSONBody(options)
+	}, SetApplicationJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []entity.Command
+
+	return commands, json.Unmarshal(res, &commands)
+}
+
+// setCommands sets the commands of a scope and language on the telegram server.
+func (b *bot) setCommands(ctx context.Context, commands entity.Commands) error {
+	_, err := b.SendRawRequestContext(ctx, http.MethodPost, "setMyCommands", func() (io.Reader, BodyOptions, error) {
+		return GetJSONBody(commands)
+	}, SetApplicationJSON)
+
+	return err
+}
+
+// equalCommands reports whether a and b hold the same commands in the same order.
+func equalCommands(a, b []entity.Command) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validCommand reports whether name is a valid command for the telegram server.
+// It can contain only lowercase English letters, digits and underscores and has 1-32 characters.
+func validCommand(name string) bool {
+	if name == "" || len(name) > 32 {
+		return false
+	}
+
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+
+	return true
+}
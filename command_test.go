@@ -0,0 +1,108 @@
+package gotbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestRegisterMethodValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		description string
+		wantErr     bool
+	}{
+		{name: "valid", command: "start_2", description: "start the bot"},
+		{name: "cyrillic description", command: "start", description: strings.Repeat("я", 256)},
+		{name: "emoji description", command: "start", description: strings.Repeat("🎉", 256)},
+		{name: "long description", command: "start", description: strings.Repeat("a", 257), wantErr: true},
+		{name: "empty description", command: "start", wantErr: true},
+		{name: "upper case", command: "Start", description: "start", wantErr: true},
+		{name: "long name", command: strings.Repeat("a", 33), description: "start", wantErr: true},
+		{name: "empty name", command: "", description: "start", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewBot("token", BotOptions{}).RegisterMethod(tt.command, tt.description, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterMethod() error = %v; want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// commandServer is a stub server that keeps the commands of the default scope and language.
+type commandServer struct {
+	mu       sync.Mutex
+	commands []entity.Command
+	methods  []string
+}
+
+func (s *commandServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	s.methods = append(s.methods, method)
+
+	var result any = true
+	switch method {
+	case "getMyCommands":
+		result = s.commands
+		if s.commands == nil {
+			result = []entity.Command{}
+		}
+	case "setMyCommands":
+		body, _ := io.ReadAll(r.Body)
+		var commands entity.Commands
+		_ = json.Unmarshal(body, &commands)
+		s.commands = commands.Commands
+	case "deleteMyCommands":
+		s.commands = nil
+	}
+
+	content, _ := json.Marshal(result)
+	_, _ = fmt.Fprintf(w, `{"ok":true,"result":%s}`, content)
+}
+
+func TestSyncCommands(t *testing.T) {
+	server := &commandServer{}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	b := NewBot("token", BotOptions{APIEndpoint: httpServer.URL})
+	_ = b.RegisterMethod("start", "start the bot", nil)
+	_ = b.RegisterMethod("help", "show help", nil)
+
+	steps := []struct {
+		name string
+		act  func()
+		want []string
+	}{
+		{name: "publish", act: func() {}, want: []string{"getMyCommands", "setMyCommands"}},
+		{name: "unchanged", act: func() {}, want: []string{"getMyCommands"}},
+		{name: "replaced", act: func() { _ = b.RegisterMethod("help", "show the help", nil) }, want: []string{"getMyCommands", "setMyCommands"}},
+		{name: "all removed", act: func() { b.UnregisterMethod("start"); b.UnregisterMethod("help") }, want: []string{"getMyCommands", "deleteMyCommands"}},
+	}
+
+	for _, step := range steps {
+		server.methods = nil
+		step.act()
+		if err := b.SyncCommands(); err != nil {
+			t.Fatalf("%s: SyncCommands() error = %v", step.name, err)
+		}
+		if !reflect.DeepEqual(server.methods, step.want) {
+			t.Errorf("%s: requests %v; want %v", step.name, server.methods, step.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package gotbot
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -32,32 +33,41 @@ var (
 	// GetJSONBody marshals a given object to a json serialized string
 	GetJSONBody = func(value any) (io.Reader, BodyOptions, error) {
 		body, err := json.Marshal(value)
-		return bytes.NewBuffer(body), BodyOptions{ContentType: "application/json"}, err
+		return bytes.NewBuffer(body), BodyOptions{ContentType: "application/json", ChatID: chatIDOf(value)}, err
 	}
 	// GetMultipartBody creates a form data with the given fields and files.
 	// if `files` contains an element with the same name in `msg`, only the file is added to the body.
 	GetMultipartBody = func(msg any, attachedFiles ...entity.FileEnvelop) (io.Reader, BodyOptions, error) {
+		return GetMultipartBodyContext(context.Background(), msg, attachedFiles...)
+	}
+	// GetMultipartBodyContext is the same as GetMultipartBody
+	// but stops building the body as soon as ctx is done.
+	GetMultipartBodyContext = func(ctx context.Context, msg any, attachedFiles ...entity.FileEnvelop) (io.Reader, BodyOptions, error) {
 		body := &bytes.Buffer{}
 		writer := multipart.NewWriter(body)
 
-		if err := writeFields(msg, body, writer); err != nil {
+		if err := writeFields(ctx, msg, body, writer); err != nil {
 			return nil, BodyOptions{}, err
 		}
 
 		for _, v := range attachedFiles {
-			if err := v.SetValue(writer, ""); err != nil {
+			if err := v.SetValueContext(ctx, writer, ""); err != nil {
 				return nil, BodyOptions{}, err
 			}
 		}
 
-		return body, BodyOptions{ContentType: writer.FormDataContentType()}, writer.Close()
+		return body, BodyOptions{ContentType: writer.FormDataContentType(), ChatID: chatIDOf(msg)}, writer.Close()
 	}
 )
 
-func writeFields(msg any, body *bytes.Buffer, writer *multipart.Writer) error {
+func writeFields(ctx context.Context, msg any, body *bytes.Buffer, writer *multipart.Writer) error {
 	msgValue := reflect.ValueOf(msg)
 
 	for i := 0; i < msgValue.NumField(); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		msgValue := reflect.ValueOf(msg)
 		fieldName := coalesce(
 			strings.Split(reflect.TypeOf(msg).Field(i).Tag.Get("json"), ",")[0],
@@ -79,7 +89,7 @@ func writeFields(msg any, body *bytes.Buffer, writer *multipart.Writer) error {
 		if msgValue.Field(i).Type() == reflect.TypeOf(&entity.FileEnvelop{}) {
 			if err := msgValue.Field(i).
 				Interface().(*entity.FileEnvelop).
-				SetValue(writer, fieldName); err != nil {
+				SetValueContext(ctx, writer, fieldName); err != nil {
 				return err
 			}
 		} else {
@@ -88,7 +98,7 @@ func writeFields(msg any, body *bytes.Buffer, writer *multipart.Writer) error {
 				reflect.Array, reflect.Slice,
 				reflect.Interface:
 				if reflect.TypeOf(msg).Field(i).Anonymous {
-					if err := writeFields(msgValue.Field(i).Interface(), body, writer); err != nil {
+					if err := writeFields(ctx, msgValue.Field(i).Interface(), body, writer); err != nil {
 						return err
 					}
 				}
@@ -113,8 +123,33 @@ func writeFields(msg any, body *bytes.Buffer, writer *multipart.Writer) error {
 	return nil
 }
 
+// BodyOptions hold information about a request body.
 type BodyOptions struct {
+	// ContentType is the content type of the body.
 	ContentType string
+	// ChatID is the target chat of the request, if there is any.
+	// It is used by the RateLimiter.
+	ChatID string
+}
+
+// chatIDOf returns the value of the `chat_id` field of value if it is a struct with such a field.
+func chatIDOf(value any) string {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		if strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0] == "chat_id" {
+			if v.Field(i).IsZero() {
+				return ""
+			}
+
+			return fmt.Sprintf("%v", v.Field(i).Interface())
+		}
+	}
+
+	return ""
 }
 
 type MessageType string
@@ -0,0 +1,88 @@
+package gotbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestRequestContext(t *testing.T) {
+	var (
+		requests int32
+		aborted  = make(chan struct{}, 1)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case <-r.Context().Done():
+			aborted <- struct{}{}
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	b := NewBot("token", BotOptions{APIEndpoint: server.URL})
+
+	t.Run("deadline during the request", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := b.GetMeContext(ctx)
+		if err != ctx.Err() || err != context.DeadlineExceeded {
+			t.Fatalf("GetMeContext() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("GetMeContext() returned after %s", elapsed)
+		}
+
+		select {
+		case <-aborted:
+		case <-time.After(2 * time.Second):
+			t.Error("the http request wasn't aborted")
+		}
+	})
+
+	t.Run("cancelled before the request", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := b.GetMeContext(ctx); err != context.Canceled {
+			t.Fatalf("GetMeContext() error = %v, want %v", err, context.Canceled)
+		}
+		if n := atomic.LoadInt32(&requests); n != 0 {
+			t.Errorf("%d requests sent with a cancelled context", n)
+		}
+	})
+
+	t.Run("cancelled while building a multipart body", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		path := filepath.Join(t.TempDir(), "photo.jpg")
+		if err := os.WriteFile(path, make([]byte, 1<<20), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var sent entity.Message
+		err := b.SendMessageAnyContext(ctx, MessagePhoto, entity.MessageEnvelop{
+			ChatID: "1",
+			Photo:  &entity.FileEnvelop{Path: "file://" + path},
+		}, &sent)
+		if err != context.Canceled {
+			t.Fatalf("SendMessageAnyContext() error = %v, want %v", err, context.Canceled)
+		}
+		if n := atomic.LoadInt32(&requests); n != 0 {
+			t.Errorf("%d requests sent with a cancelled context", n)
+		}
+	})
+}
@@ -0,0 +1,270 @@
+package conversation
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/filter"
+	"github.com/roskee/gotbot/router"
+)
+
+// DefaultCancelCommand is the command that cancels a conversation if Options.CancelCommand is not set.
+const DefaultCancelCommand = "cancel"
+
+// ErrUnknownState is returned by Context.Next if the state was not added to the conversation.
+var ErrUnknownState = errors.New("unknown conversation state")
+
+// HandlerFunc handles an update of a conversation.
+type HandlerFunc func(c *Context)
+
+// Options hold the options of a Conversation.
+type Options struct {
+	// Storage stores the state of the conversations. Defaults to a new MemoryStorage.
+	Storage Storage
+	// Timeout ends a conversation that didn't move for the given duration. Zero means no timeout.
+	// It is only checked when the next update of the conversation arrives,
+	// nothing runs in the background, so an idle conversation stays in Storage until then.
+	Timeout time.Duration
+	// OnTimeout is called with the update that arrived after the conversation timed out.
+	// The update is not handled otherwise.
+	OnTimeout HandlerFunc
+	// CancelCommand is the command that ends the conversation at any state.
+	// Defaults to DefaultCancelCommand.
+	CancelCommand string
+	// OnCancel is called when the conversation is ended with CancelCommand.
+	OnCancel HandlerFunc
+	// Logger logs the errors of Storage. Nothing is logged if it is nil.
+	Logger router.Logger
+}
+
+// Conversation is a state machine for a dialog with a user.
+// Every chat and user pair has its own state.
+//
+// A conversation is started by one of its entry points and moves between its named states
+// until it is ended, canceled or timed out:
+//
+//	form := conversation.New(conversation.Options{Timeout: 10 * time.Minute})
+//	form.State("name", func(c *conversation.Context) {
+//		c.Data["name"] = c.Message.Text
+//		_ = c.Next("age")
+//	})
+//	form.State("age", func(c *conversation.Context) {
+//		save(c.Data["name"], c.Message.Text)
+//		c.End()
+//	})
+//
+//	r.Command("register", form.Entry(func(c *conversation.Context) {
+//		_ = c.Next("name")
+//	}))
+//	r.On(form.Active(), form.Handle)
+type Conversation struct {
+	options Options
+
+	mu     sync.RWMutex
+	states map[string]HandlerFunc
+}
+
+// New returns a new Conversation without states.
+func New(options Options) *Conversation {
+	if options.Storage == nil {
+		options.Storage = NewMemoryStorage()
+	}
+	if options.CancelCommand == "" {
+		options.CancelCommand = DefaultCancelCommand
+	}
+
+	return &Conversation{
+		options: options,
+		states:  map[string]HandlerFunc{},
+	}
+}
+
+// State adds the state name with its handler.
+// The handler is called for every update of a conversation in that state.
+// A conversation stays in its state unless the handler calls Context.Next or Context.End.
+func (cv *Conversation) State(name string, handler HandlerFunc) {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+
+	cv.states[name] = handler
+}
+
+// Entry returns a router handler that starts the conversation with handler.
+// It can be registered as a command or a callback handler.
+// The conversation is only started if handler calls Context.Next,
+// a conversation already going on is restarted.
+func (cv *Conversation) Entry(handler HandlerFunc) router.HandlerFunc {
+	return func(rc *router.Context) {
+		key, ok := KeyOf(rc.Update)
+		if !ok {
+			return
+		}
+
+		c := cv.newContext(rc, key, Record{Data: map[string]string{}})
+		handler(c)
+		cv.save(c)
+	}
+}
+
+// Active returns a filter that matches updates of conversations that are going on.
+func (cv *Conversation) Active() filter.Filter {
+	return func(update entity.Update) bool {
+		key, ok := KeyOf(update)
+		if !ok {
+			return false
+		}
+
+		_, ok = cv.load(key)
+
+		return ok
+	}
+}
+
+// Handle is the router handler of updates of conversations that are going on.
+// It is registered along with the Active filter.
+func (cv *Conversation) Handle(rc *router.Context) {
+	key, ok := KeyOf(rc.Update)
+	if !ok {
+		return
+	}
+	record, ok := cv.load(key)
+	if !ok {
+		return
+	}
+
+	c := cv.newContext(rc, key, record)
+
+	if cv.options.Timeout != 0 && time.Since(record.UpdatedAt) > cv.options.Timeout {
+		c.End()
+		cv.save(c)
+		if cv.options.OnTimeout != nil {
+			cv.options.OnTimeout(c)
+		}
+		return
+	}
+
+	if command, ok := router.ParseCommand(rc.Message); ok && command.Name == cv.options.CancelCommand {
+		c.End()
+		cv.save(c)
+		if cv.options.OnCancel != nil {
+			cv.options.OnCancel(c)
+		}
+		return
+	}
+
+	cv.mu.RLock()
+	handler, ok := cv.states[record.State]
+	cv.mu.RUnlock()
+	if !ok {
+		cv.log("conversation is in an unknown state", key, fmt.Errorf("%w: %s", ErrUnknownState, record.State))
+		c.End()
+		cv.save(c)
+		return
+	}
+
+	handler(c)
+	cv.save(c)
+}
+
+// Cancel ends the conversation of key.
+func (cv *Conversation) Cancel(key Key) error {
+	return cv.options.Storage.Delete(key)
+}
+
+func (cv *Conversation) newContext(rc *router.Context, key Key, record Record) *Context {
+	if record.Data == nil {
+		record.Data = map[string]string{}
+	}
+
+	return &Context{
+		Context: rc,
+		Key:     key,
+		Data:    record.Data,
+		state:   record.State,
+		next:    record.State,
+		cv:      cv,
+	}
+}
+
+func (cv *Conversation) load(key Key) (Record, bool) {
+	record, ok, err := cv.options.Storage.Load(key)
+	if err != nil {
+		cv.log("failed to load the conversation", key, err)
+		return Record{}, false
+	}
+
+	return record, ok
+}
+
+// save stores the state the context moved to or deletes it if the conversation has ended.
+func (cv *Conversation) save(c *Context) {
+	var err error
+	if c.next == "" {
+		err = cv.options.Storage.Delete(c.Key)
+	} else {
+		err = cv.options.Storage.Save(c.Key, Record{
+			State:     c.next,
+			Data:      c.Data,
+			UpdatedAt: time.Now(),
+		})
+	}
+
+	if err != nil {
+		cv.log("failed to save the conversation", c.Key, err)
+	}
+}
+
+func (cv *Conversation) log(msg string, key Key, err error) {
+	if cv.options.Logger == nil {
+		return
+	}
+
+	cv.options.Logger.Error(msg, map[string]any{
+		"conversation": key.String(),
+		"error":        err.Error(),
+	})
+}
+
+// Context is the router context of an update along with the state of its conversation.
+type Context struct {
+	*router.Context
+	// Key is the key of the conversation.
+	Key Key
+	// Data holds the values collected in the conversation so far.
+	// It is saved along with the state after the handler returns.
+	Data map[string]string
+
+	state string
+	next  string
+	cv    *Conversation
+}
+
+// State returns the state the conversation was in when the update arrived.
+// It is empty in an entry point.
+func (c *Context) State() string {
+	return c.state
+}
+
+// Next moves the conversation to state after the handler returns.
+// It returns ErrUnknownState if state was not added to the conversation.
+func (c *Context) Next(state string) error {
+	c.cv.mu.RLock()
+	_, ok := c.cv.states[state]
+	c.cv.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownState, state)
+	}
+
+	c.next = state
+
+	return nil
+}
+
+// End ends the conversation after the handler returns.
+// Its data is discarded.
+func (c *Context) End() {
+	c.next = ""
+}
@@ -0,0 +1,152 @@
+package conversation
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/router"
+)
+
+type fakeAPI struct{}
+
+func (fakeAPI) GetMe() (entity.User, error) {
+	return entity.User{UserName: "MyBot"}, nil
+}
+
+func (fakeAPI) AnswerCallbackQuery(entity.AnswerCallbackQueryEntity) error {
+	return nil
+}
+
+func text(s string) entity.Update {
+	return entity.Update{Message: &entity.Message{
+		Text: s,
+		Chat: &entity.Chat{ID: 10},
+		From: &entity.User{ID: 1},
+	}}
+}
+
+var key = Key{ChatID: 10, UserID: 1}
+
+// newForm returns a router with a conversation asking for a name and an age.
+func newForm(options Options, registered *[]string) (*router.Router, *Conversation) {
+	form := New(options)
+	form.State("name", func(c *Context) {
+		c.Data["name"] = c.Message.Text
+		_ = c.Next("age")
+	})
+	form.State("age", func(c *Context) {
+		*registered = append(*registered, c.Data["name"]+" "+c.Message.Text)
+		c.End()
+	})
+
+	r := router.New(fakeAPI{})
+	r.Command("register", form.Entry(func(c *Context) {
+		_ = c.Next("name")
+	}))
+	r.On(form.Active(), form.Handle)
+
+	return r, form
+}
+
+func TestConversationFlow(t *testing.T) {
+	var registered []string
+	storage := NewMemoryStorage()
+	r, _ := newForm(Options{Storage: storage}, &registered)
+
+	r.HandleUpdate(text("/register"))
+	r.HandleUpdate(text("Abebe"))
+	if record, _, _ := storage.Load(key); record.State != "age" || record.Data["name"] != "Abebe" {
+		t.Fatalf("record = %+v; want the age state with the name", record)
+	}
+
+	r.HandleUpdate(text("30"))
+	if _, ok, _ := storage.Load(key); ok {
+		t.Error("the conversation is still stored after it ended")
+	}
+	if want := []string{"Abebe 30"}; !reflect.DeepEqual(registered, want) {
+		t.Errorf("registered %v; want %v", registered, want)
+	}
+}
+
+func TestConversationCancelAndTimeout(t *testing.T) {
+	var registered []string
+	var events []string
+	storage := NewMemoryStorage()
+	r, _ := newForm(Options{
+		Storage:   storage,
+		Timeout:   time.Minute,
+		OnCancel:  func(c *Context) { events = append(events, "cancel") },
+		OnTimeout: func(c *Context) { events = append(events, "timeout "+c.Message.Text) },
+	}, &registered)
+
+	r.HandleUpdate(text("/register"))
+	r.HandleUpdate(text("/cancel"))
+	if _, ok, _ := storage.Load(key); ok {
+		t.Error("the conversation is still stored after it was canceled")
+	}
+
+	_ = storage.Save(key, Record{State: "name", UpdatedAt: time.Now().Add(-2 * time.Minute)})
+	r.HandleUpdate(text("Abebe"))
+	if _, ok, _ := storage.Load(key); ok {
+		t.Error("the conversation is still stored after it timed out")
+	}
+
+	if want := []string{"cancel", "timeout Abebe"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events %v; want %v", events, want)
+	}
+	if len(registered) != 0 {
+		t.Errorf("registered %v after cancel and timeout", registered)
+	}
+}
+
+func TestNextUnknownState(t *testing.T) {
+	form := New(Options{})
+	c := &Context{cv: form}
+
+	if err := c.Next("missing"); err == nil {
+		t.Error("Next() of an unknown state succeeded")
+	}
+}
+
+func TestMemoryStorageCopiesData(t *testing.T) {
+	storage := NewMemoryStorage()
+	data := map[string]string{"name": "Abebe"}
+	_ = storage.Save(key, Record{State: "age", Data: data})
+	data["name"] = "changed"
+
+	first, _, _ := storage.Load(key)
+	first.Data["name"] = "changed too"
+
+	second, _, _ := storage.Load(key)
+	if second.Data["name"] != "Abebe" {
+		t.Errorf("stored data = %v; want it unaffected by callers", second.Data)
+	}
+}
+
+// TestConcurrentUpdates is meant to be run with -race.
+func TestConcurrentUpdates(t *testing.T) {
+	form := New(Options{})
+	form.State("collect", func(c *Context) {
+		c.Data[c.Message.Text] = c.Message.Text
+	})
+	r := router.New(fakeAPI{})
+	r.Command("start", form.Entry(func(c *Context) {
+		_ = c.Next("collect")
+	}))
+	r.On(form.Active(), form.Handle)
+
+	r.HandleUpdate(text("/start"))
+
+	var wg sync.WaitGroup
+	for _, s := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func(s string) {
+			defer wg.Done()
+			r.HandleUpdate(text(s))
+		}(s)
+	}
+	wg.Wait()
+}
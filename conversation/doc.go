@@ -0,0 +1,4 @@
+// Package conversation contains a state machine for dialogs that span multiple updates,
+// like registration forms or checkouts.
+// A conversation is kept for every chat and user pair and is plugged into a router.
+package conversation
@@ -0,0 +1,114 @@
+package conversation
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// Key identifies a conversation. There is one conversation per user in every chat.
+type Key struct {
+	ChatID int64
+	UserID int64
+}
+
+// KeyOf returns the key of the conversation update belongs to.
+// The second return value is false if the update has neither a chat nor a sender.
+func KeyOf(update entity.Update) (Key, bool) {
+	var key Key
+
+	chat, user := update.GetChat(), update.GetFrom()
+	if chat != nil {
+		key.ChatID = chat.ID
+	}
+	if user != nil {
+		key.UserID = user.ID
+	}
+
+	return key, chat != nil || user != nil
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%d:%d", k.ChatID, k.UserID)
+}
+
+// Record is the stored state of a conversation.
+type Record struct {
+	// State is the name of the current state.
+	State string `json:"state"`
+	// Data holds the values collected so far.
+	Data map[string]string `json:"data,omitempty"`
+	// UpdatedAt is the last time the conversation moved.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Storage stores the state of conversations.
+// Implementations must be safe for concurrent use
+// and must not share the Data of a record between calls, since handlers modify it.
+type Storage interface {
+	// Load returns the record stored under key.
+	// The second return value is false if there is no such record.
+	Load(key Key) (Record, bool, error)
+	// Save stores record under key.
+	Save(key Key, record Record) error
+	// Delete removes the record stored under key, if there is any.
+	Delete(key Key) error
+}
+
+// MemoryStorage is a Storage that keeps the records in memory.
+// The records are lost when the program exits.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	records map[Key]Record
+}
+
+// NewMemoryStorage returns a new empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		records: map[Key]Record{},
+	}
+}
+
+func (s *MemoryStorage) Load(key Key) (Record, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.records[key]
+	record.Data = copyData(record.Data)
+
+	return record, ok, nil
+}
+
+func (s *MemoryStorage) Save(key Key, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.Data = copyData(record.Data)
+	s.records[key] = record
+
+	return nil
+}
+
+func (s *MemoryStorage) Delete(key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+func copyData(data map[string]string) map[string]string {
+	if data == nil {
+		return nil
+	}
+
+	copied := make(map[string]string, len(data))
+	for k, v := range data {
+		copied[k] = v
+	}
+
+	return copied
+}
@@ -1,6 +1,7 @@
 package gotbot
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -11,7 +12,12 @@ import (
 
 // GetMe is the implementation of the builtin getMe function of the bot
 func (b *bot) GetMe() (entity.User, error) {
-	body, err := b.SendRawRequest(http.MethodGet, "getMe", nil, nil)
+	return b.GetMeContext(context.Background())
+}
+
+// GetMeContext is the same as GetMe but carries ctx to the request.
+func (b *bot) GetMeContext(ctx context.Context) (entity.User, error) {
+	body, err := b.SendRawRequestContext(ctx, http.MethodGet, "getMe", nil, nil)
 	if err != nil {
 		return entity.User{}, err
 	}
@@ -23,33 +29,52 @@ func (b *bot) GetMe() (entity.User, error) {
 // GetMyCommands is the implementation of the builtin getMyCommands function of the bot.
 // It returns the list of all currently registered commands
 func (b *bot) GetMyCommands() ([]entity.Command, error) {
-	res, err := b.SendRawRequest(http.MethodGet, "getMyCommands", nil, nil)
-	if err != nil {
-		return nil, err
-	}
-	var commands []entity.Command
-	if err := json.Unmarshal(res, &commands); err != nil {
-		return nil, err
-	}
-	return commands, nil
+	return b.GetMyCommandsContext(context.Background())
+}
+
+// GetMyCommandsContext is the same as GetMyCommands but carries ctx to the request.
+func (b *bot) GetMyCommandsContext(ctx context.Context) ([]entity.Command, error) {
+	return b.getCommands(ctx, envelop.GetMyCommandsEnvelop{})
+}
+
+func (b *bot) GetMyCommandsWithOptions(options envelop.GetMyCommandsEnvelop) ([]entity.Command, error) {
+	return b.GetMyCommandsWithOptionsContext(context.Background(), options)
+}
+
+// GetMyCommandsWithOptionsContext is the same as GetMyCommandsWithOptions but carries ctx to the request.
+func (b *bot) GetMyCommandsWithOptionsContext(ctx context.Context, options envelop.GetMyCommandsEnvelop) ([]entity.Command, error) {
+	return b.getCommands(ctx, options)
 }
 
 // SetMyCommands is the implementation of the builtin setMyCommands function of the bot.
 // It sets the given commands as the bot's command
 func (b *bot) SetMyCommands(commands []entity.Command) error {
-	_, err := b.SendRawRequest(http.MethodPost, "setMyCommands", func() (io.Reader, BodyOptions, error) {
-		return GetJSONBody(entity.Commands{
-			Commands: commands,
-		})
-	}, SetApplicationJSON)
-	if err != nil {
-		return err
-	}
-	return nil
+	return b.SetMyCommandsContext(context.Background(), commands)
+}
+
+// SetMyCommandsContext is the same as SetMyCommands but carries ctx to the request.
+func (b *bot) SetMyCommandsContext(ctx context.Context, commands []entity.Command) error {
+	return b.setCommands(ctx, entity.Commands{
+		Commands: commands,
+	})
+}
+
+func (b *bot) SetMyCommandsWithOptions(commands entity.Commands) error {
+	return b.SetMyCommandsWithOptionsContext(context.Background(), commands)
+}
+
+// SetMyCommandsWithOptionsContext is the same as SetMyCommandsWithOptions but carries ctx to the request.
+func (b *bot) SetMyCommandsWithOptionsContext(ctx context.Context, commands entity.Commands) error {
+	return b.setCommands(ctx, commands)
 }
 
 func (b *bot) DeleteMyCommands(commandScope envelop.DeleteMyCommandsEnvelop) (bool, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "deleteMyCommands", func() (io.Reader, BodyOptions, error) {
+	return b.DeleteMyCommandsContext(context.Background(), commandScope)
+}
+
+// DeleteMyCommandsContext is the same as DeleteMyCommands but carries ctx to the request.
+func (b *bot) DeleteMyCommandsContext(ctx context.Context, commandScope envelop.DeleteMyCommandsEnvelop) (bool, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "deleteMyCommands", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(commandScope)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -63,22 +88,37 @@ func (b *bot) DeleteMyCommands(commandScope envelop.DeleteMyCommandsEnvelop) (bo
 // SendMessage is the implementation of the builtin sendMessage function of the bot.
 // It sends the given message to the sender user
 func (b *bot) SendMessage(message entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendMessageContext(context.Background(), message)
+}
+
+// SendMessageContext is the same as SendMessage but carries ctx to the request.
+func (b *bot) SendMessageContext(ctx context.Context, message entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageText, message, &res)
+	err := b.SendMessageAnyContext(ctx, MessageText, message, &res)
 
 	return res, err
 }
 
 func (b *bot) AnswerCallbackQuery(options entity.AnswerCallbackQueryEntity) error {
-	_, err := b.SendRawRequest(http.MethodPost, "answerCallbackQuery", func() (io.Reader, BodyOptions, error) {
+	return b.AnswerCallbackQueryContext(context.Background(), options)
+}
+
+// AnswerCallbackQueryContext is the same as AnswerCallbackQuery but carries ctx to the request.
+func (b *bot) AnswerCallbackQueryContext(ctx context.Context, options entity.AnswerCallbackQueryEntity) error {
+	_, err := b.SendRawRequestContext(ctx, http.MethodPost, "answerCallbackQuery", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(options)
 	}, SetApplicationJSON)
 	return err
 }
 
 func (b *bot) ForwardMessage(msgEnvelop envelop.ForwardMessageEnvelop) (entity.Message, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "forwardMessage", func() (io.Reader, BodyOptions, error) {
+	return b.ForwardMessageContext(context.Background(), msgEnvelop)
+}
+
+// ForwardMessageContext is the same as ForwardMessage but carries ctx to the request.
+func (b *bot) ForwardMessageContext(ctx context.Context, msgEnvelop envelop.ForwardMessageEnvelop) (entity.Message, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "forwardMessage", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msgEnvelop)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -91,7 +131,12 @@ func (b *bot) ForwardMessage(msgEnvelop envelop.ForwardMessageEnvelop) (entity.M
 }
 
 func (b *bot) CopyMessage(msgEnvelop envelop.CopyMessageEnvelop) (int64, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "copyMessage", func() (io.Reader, BodyOptions, error) {
+	return b.CopyMessageContext(context.Background(), msgEnvelop)
+}
+
+// CopyMessageContext is the same as CopyMessage but carries ctx to the request.
+func (b *bot) CopyMessageContext(ctx context.Context, msgEnvelop envelop.CopyMessageEnvelop) (int64, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "copyMessage", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msgEnvelop)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -104,78 +149,128 @@ func (b *bot) CopyMessage(msgEnvelop envelop.CopyMessageEnvelop) (int64, error)
 }
 
 func (b *bot) SendPhoto(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendPhotoContext(context.Background(), msg)
+}
+
+// SendPhotoContext is the same as SendPhoto but carries ctx to the request.
+func (b *bot) SendPhotoContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessagePhoto, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessagePhoto, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendAudio(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendAudioContext(context.Background(), msg)
+}
+
+// SendAudioContext is the same as SendAudio but carries ctx to the request.
+func (b *bot) SendAudioContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageAudio, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageAudio, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendVideo(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendVideoContext(context.Background(), msg)
+}
+
+// SendVideoContext is the same as SendVideo but carries ctx to the request.
+func (b *bot) SendVideoContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageVideo, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageVideo, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendLocation(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendLocationContext(context.Background(), msg)
+}
+
+// SendLocationContext is the same as SendLocation but carries ctx to the request.
+func (b *bot) SendLocationContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageLocation, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageLocation, msg, &res)
 	return res, err
 }
 
 func (b *bot) SendDocument(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendDocumentContext(context.Background(), msg)
+}
+
+// SendDocumentContext is the same as SendDocument but carries ctx to the request.
+func (b *bot) SendDocumentContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageDocument, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageDocument, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendVoice(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendVoiceContext(context.Background(), msg)
+}
+
+// SendVoiceContext is the same as SendVoice but carries ctx to the request.
+func (b *bot) SendVoiceContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageVoice, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageVoice, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendMediaGroup(msg entity.MessageEnvelop) ([]entity.Message, error) {
+	return b.SendMediaGroupContext(context.Background(), msg)
+}
+
+// SendMediaGroupContext is the same as SendMediaGroup but carries ctx to the request.
+func (b *bot) SendMediaGroupContext(ctx context.Context, msg entity.MessageEnvelop) ([]entity.Message, error) {
 	var res []entity.Message
 
-	err := b.SendMessageAny(MessageMediaGroup, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageMediaGroup, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendVideoNote(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendVideoNoteContext(context.Background(), msg)
+}
+
+// SendVideoNoteContext is the same as SendVideoNote but carries ctx to the request.
+func (b *bot) SendVideoNoteContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageVideoNote, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageVideoNote, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendContact(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendContactContext(context.Background(), msg)
+}
+
+// SendContactContext is the same as SendContact but carries ctx to the request.
+func (b *bot) SendContactContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageContact, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageContact, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) GetUserProfilePhotos(options envelop.GetUserProfilePhotos) (entity.UserProfilePhotos, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "getUserProfilePhotos", func() (io.Reader, BodyOptions, error) {
+	return b.GetUserProfilePhotosContext(context.Background(), options)
+}
+
+// GetUserProfilePhotosContext is the same as GetUserProfilePhotos but carries ctx to the request.
+func (b *bot) GetUserProfilePhotosContext(ctx context.Context, options envelop.GetUserProfilePhotos) (entity.UserProfilePhotos, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "getUserProfilePhotos", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(options)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -187,8 +282,31 @@ func (b *bot) GetUserProfilePhotos(options envelop.GetUserProfilePhotos) (entity
 	return photos, json.Unmarshal(res, &photos)
 }
 
+func (b *bot) GetChatMember(options envelop.GetChatMember) (entity.ChatMember, error) {
+	return b.GetChatMemberContext(context.Background(), options)
+}
+
+// GetChatMemberContext is the same as GetChatMember but carries ctx to the request.
+func (b *bot) GetChatMemberContext(ctx context.Context, options envelop.GetChatMember) (entity.ChatMember, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "getChatMember", func() (io.Reader, BodyOptions, error) {
+		return GetJSONBody(options)
+	}, SetApplicationJSON)
+	if err != nil {
+		return entity.ChatMember{}, err
+	}
+
+	var member entity.ChatMember
+
+	return member, json.Unmarshal(res, &member)
+}
+
 func (b *bot) GetFile(getFile envelop.GetFile) (entity.File, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "getFile", func() (io.Reader, BodyOptions, error) {
+	return b.GetFileContext(context.Background(), getFile)
+}
+
+// GetFileContext is the same as GetFile but carries ctx to the request.
+func (b *bot) GetFileContext(ctx context.Context, getFile envelop.GetFile) (entity.File, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "getFile", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(getFile)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -201,47 +319,77 @@ func (b *bot) GetFile(getFile envelop.GetFile) (entity.File, error) {
 }
 
 func (b *bot) SendPoll(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendPollContext(context.Background(), msg)
+}
+
+// SendPollContext is the same as SendPoll but carries ctx to the request.
+func (b *bot) SendPollContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessagePoll, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessagePoll, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendChatAction(msg entity.MessageEnvelop) (bool, error) {
+	return b.SendChatActionContext(context.Background(), msg)
+}
+
+// SendChatActionContext is the same as SendChatAction but carries ctx to the request.
+func (b *bot) SendChatActionContext(ctx context.Context, msg entity.MessageEnvelop) (bool, error) {
 	var res bool
 
-	err := b.SendMessageAny(MessageChatAction, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageChatAction, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendAnimation(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendAnimationContext(context.Background(), msg)
+}
+
+// SendAnimationContext is the same as SendAnimation but carries ctx to the request.
+func (b *bot) SendAnimationContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageAnimation, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageAnimation, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendDice(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendDiceContext(context.Background(), msg)
+}
+
+// SendDiceContext is the same as SendDice but carries ctx to the request.
+func (b *bot) SendDiceContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageDice, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageDice, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SendVenue(msg entity.MessageEnvelop) (entity.Message, error) {
+	return b.SendVenueContext(context.Background(), msg)
+}
+
+// SendVenueContext is the same as SendVenue but carries ctx to the request.
+func (b *bot) SendVenueContext(ctx context.Context, msg entity.MessageEnvelop) (entity.Message, error) {
 	var res entity.Message
 
-	err := b.SendMessageAny(MessageVenue, msg, &res)
+	err := b.SendMessageAnyContext(ctx, MessageVenue, msg, &res)
 
 	return res, err
 }
 
 func (b *bot) SetChatAdministratorCustomTitle(title envelop.SetChatAdministratorCustomTitle) (bool, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "setChatAdministratorCustomTitle", func() (io.Reader, BodyOptions, error) {
+	return b.SetChatAdministratorCustomTitleContext(context.Background(), title)
+}
+
+// SetChatAdministratorCustomTitleContext is the same as SetChatAdministratorCustomTitle but carries ctx to the request.
+func (b *bot) SetChatAdministratorCustomTitleContext(ctx context.Context, title envelop.SetChatAdministratorCustomTitle) (bool, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "setChatAdministratorCustomTitle", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(title)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -254,7 +402,12 @@ func (b *bot) SetChatAdministratorCustomTitle(title envelop.SetChatAdministrator
 }
 
 func (b *bot) EditMessageText(msg envelop.EditMessageTextEnvelop) (entity.Message, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "editMessageText", func() (io.Reader, BodyOptions, error) {
+	return b.EditMessageTextContext(context.Background(), msg)
+}
+
+// EditMessageTextContext is the same as EditMessageText but carries ctx to the request.
+func (b *bot) EditMessageTextContext(ctx context.Context, msg envelop.EditMessageTextEnvelop) (entity.Message, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "editMessageText", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msg)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -267,7 +420,12 @@ func (b *bot) EditMessageText(msg envelop.EditMessageTextEnvelop) (entity.Messag
 }
 
 func (b *bot) EditMessageCaption(msg envelop.EditMessageCaptionEnvelop) (entity.Message, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "editMessageCaption", func() (io.Reader, BodyOptions, error) {
+	return b.EditMessageCaptionContext(context.Background(), msg)
+}
+
+// EditMessageCaptionContext is the same as EditMessageCaption but carries ctx to the request.
+func (b *bot) EditMessageCaptionContext(ctx context.Context, msg envelop.EditMessageCaptionEnvelop) (entity.Message, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "editMessageCaption", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msg)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -280,7 +438,12 @@ func (b *bot) EditMessageCaption(msg envelop.EditMessageCaptionEnvelop) (entity.
 }
 
 func (b *bot) EditMessageMedia(msg envelop.EditMessageMediaEnvelop) (entity.Message, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "editMessageMedia", func() (io.Reader, BodyOptions, error) {
+	return b.EditMessageMediaContext(context.Background(), msg)
+}
+
+// EditMessageMediaContext is the same as EditMessageMedia but carries ctx to the request.
+func (b *bot) EditMessageMediaContext(ctx context.Context, msg envelop.EditMessageMediaEnvelop) (entity.Message, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "editMessageMedia", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msg)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -293,7 +456,12 @@ func (b *bot) EditMessageMedia(msg envelop.EditMessageMediaEnvelop) (entity.Mess
 }
 
 func (b *bot) EditMessageLiveLocation(msg envelop.EditMessageLiveLocationEnvelop) (entity.Message, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "editMessageLiveLocation", func() (io.Reader, BodyOptions, error) {
+	return b.EditMessageLiveLocationContext(context.Background(), msg)
+}
+
+// EditMessageLiveLocationContext is the same as EditMessageLiveLocation but carries ctx to the request.
+func (b *bot) EditMessageLiveLocationContext(ctx context.Context, msg envelop.EditMessageLiveLocationEnvelop) (entity.Message, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "editMessageLiveLocation", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msg)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -306,7 +474,12 @@ func (b *bot) EditMessageLiveLocation(msg envelop.EditMessageLiveLocationEnvelop
 }
 
 func (b *bot) StopMessageLiveLocation(msg envelop.StopMessageLiveLocationEnvelop) (entity.Message, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "stopMessageLiveLocation", func() (io.Reader, BodyOptions, error) {
+	return b.StopMessageLiveLocationContext(context.Background(), msg)
+}
+
+// StopMessageLiveLocationContext is the same as StopMessageLiveLocation but carries ctx to the request.
+func (b *bot) StopMessageLiveLocationContext(ctx context.Context, msg envelop.StopMessageLiveLocationEnvelop) (entity.Message, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "stopMessageLiveLocation", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msg)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -319,7 +492,12 @@ func (b *bot) StopMessageLiveLocation(msg envelop.StopMessageLiveLocationEnvelop
 }
 
 func (b *bot) EditMessageReplyMarkup(msg envelop.EditMessageReplyMarkupEnvelop) (entity.Message, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "editMessageReplyMarkup", func() (io.Reader, BodyOptions, error) {
+	return b.EditMessageReplyMarkupContext(context.Background(), msg)
+}
+
+// EditMessageReplyMarkupContext is the same as EditMessageReplyMarkup but carries ctx to the request.
+func (b *bot) EditMessageReplyMarkupContext(ctx context.Context, msg envelop.EditMessageReplyMarkupEnvelop) (entity.Message, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "editMessageReplyMarkup", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msg)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -332,7 +510,12 @@ func (b *bot) EditMessageReplyMarkup(msg envelop.EditMessageReplyMarkupEnvelop)
 }
 
 func (b *bot) StopPoll(msg envelop.StopPollEnvelop) (entity.Poll, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "stopPoll", func() (io.Reader, BodyOptions, error) {
+	return b.StopPollContext(context.Background(), msg)
+}
+
+// StopPollContext is the same as StopPoll but carries ctx to the request.
+func (b *bot) StopPollContext(ctx context.Context, msg envelop.StopPollEnvelop) (entity.Poll, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "stopPoll", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msg)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -345,7 +528,12 @@ func (b *bot) StopPoll(msg envelop.StopPollEnvelop) (entity.Poll, error) {
 }
 
 func (b *bot) DeleteMessage(msg envelop.DeleteMessageEnvelop) (bool, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "deleteMessage", func() (io.Reader, BodyOptions, error) {
+	return b.DeleteMessageContext(context.Background(), msg)
+}
+
+// DeleteMessageContext is the same as DeleteMessage but carries ctx to the request.
+func (b *bot) DeleteMessageContext(ctx context.Context, msg envelop.DeleteMessageEnvelop) (bool, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "deleteMessage", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(msg)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -358,7 +546,12 @@ func (b *bot) DeleteMessage(msg envelop.DeleteMessageEnvelop) (bool, error) {
 }
 
 func (b *bot) SendInvoice(invoice envelop.SendInvoiceEnvelop) (entity.Message, error) {
-	res, err := b.SendRawRequest(http.MethodPost, "sendInvoice", func() (io.Reader, BodyOptions, error) {
+	return b.SendInvoiceContext(context.Background(), invoice)
+}
+
+// SendInvoiceContext is the same as SendInvoice but carries ctx to the request.
+func (b *bot) SendInvoiceContext(ctx context.Context, invoice envelop.SendInvoiceEnvelop) (entity.Message, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "sendInvoice", func() (io.Reader, BodyOptions, error) {
 		return GetJSONBody(invoice)
 	}, SetApplicationJSON)
 	if err != nil {
@@ -0,0 +1,166 @@
+package gotbot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// dispatcher runs update handlers outside the polling loop on a pool of workers
+// and keeps track of the updates whose handlers have completed.
+//
+// Updates of the same chat or user are always handled by the same worker so that they are handled in order.
+type dispatcher struct {
+	bot    *bot
+	config entity.UpdateConfig
+
+	queues []chan entity.Update
+	stop   chan struct{}
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// pending holds the ids of updates that were dispatched but not handled yet.
+	pending map[int64]struct{}
+	// next is the id following the highest dispatched update id.
+	next int64
+	// handled is closed and replaced every time an update is handled.
+	handled chan struct{}
+}
+
+// newDispatcher creates a dispatcher and starts its workers.
+func newDispatcher(b *bot, config entity.UpdateConfig) *dispatcher {
+	d := &dispatcher{
+		bot:     b,
+		config:  config,
+		queues:  make([]chan entity.Update, b.options.Concurrency),
+		stop:    make(chan struct{}),
+		pending: map[int64]struct{}{},
+		handled: make(chan struct{}),
+	}
+
+	for i := range d.queues {
+		d.queues[i] = make(chan entity.Update, 100)
+
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+
+	return d
+}
+
+// dispatch queues update to be handled and reports whether it did.
+// Updates that were dispatched before, which the telegram server sends again until they are confirmed,
+// are skipped.
+// It blocks while the queue is full and returns early with the error of ctx if ctx is done.
+func (d *dispatcher) dispatch(ctx context.Context, update entity.Update) (bool, error) {
+	d.mu.Lock()
+	if update.UpdateID < d.next {
+		d.mu.Unlock()
+		return false, nil
+	}
+	d.pending[update.UpdateID] = struct{}{}
+	d.next = update.UpdateID + 1
+	d.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case d.queues[d.worker(update)] <- update:
+		return true, nil
+	}
+}
+
+// worker returns the index of the worker that handles update.
+func (d *dispatcher) worker(update entity.Update) int {
+	key := update.UpdateID
+	if chat := update.GetChat(); chat != nil {
+		key = chat.ID
+	} else if from := update.GetFrom(); from != nil {
+		key = from.ID
+	}
+
+	if key < 0 {
+		key = -key
+	}
+
+	return int(key % int64(len(d.queues)))
+}
+
+func (d *dispatcher) work(queue chan entity.Update) {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case update := <-queue:
+			// stop has priority over queued updates, which aren't confirmed
+			// and are sent again by the telegram server the next time the bot polls.
+			select {
+			case <-d.stop:
+				return
+			default:
+			}
+
+			d.bot.executeUpdate(update, d.config)
+			d.done(update.UpdateID)
+		}
+	}
+}
+
+func (d *dispatcher) done(updateID int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.pending, updateID)
+	close(d.handled)
+	d.handled = make(chan struct{})
+}
+
+// progress returns a channel that is closed once an update is handled after the call.
+func (d *dispatcher) progress() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.handled
+}
+
+// offset returns the offset that confirms every update handled so far
+// without confirming any update that isn't handled yet.
+func (d *dispatcher) offset() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	offset := d.next
+	for updateID := range d.pending {
+		if updateID < offset {
+			offset = updateID
+		}
+	}
+
+	return offset
+}
+
+// shutdown stops handling queued updates and waits up to timeout for running handlers to complete.
+// It reports whether all running handlers completed in time.
+func (d *dispatcher) shutdown(timeout time.Duration) bool {
+	close(d.stop)
+
+	drained := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-drained:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,164 @@
+package gotbot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// panicLogger records the errors logged by the bot.
+type panicLogger struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (*panicLogger) Debug(string, Fields) {}
+func (*panicLogger) Info(string, Fields)  {}
+func (*panicLogger) Warn(string, Fields)  {}
+func (l *panicLogger) Error(msg string, _ Fields) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errors = append(l.errors, msg)
+}
+
+func chatUpdate(updateID, chatID int64) entity.Update {
+	return entity.Update{UpdateID: updateID, EditedMessage: &entity.Message{Chat: &entity.Chat{ID: chatID}}}
+}
+
+func TestDispatcherOrdersUpdatesPerChat(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		handled = map[int64][]int64{}
+	)
+	config := entity.UpdateConfig{OnUpdate: func(update entity.Update) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		chatID := update.GetChat().ID
+		handled[chatID] = append(handled[chatID], update.UpdateID)
+	}}
+
+	b := NewBot("token", BotOptions{Concurrency: 4}).(*bot)
+	d := newDispatcher(b, config)
+
+	for id := int64(1); id <= 60; id++ {
+		if _, err := d.dispatch(context.Background(), chatUpdate(id, -id%3)); err != nil {
+			t.Fatalf("dispatch() error = %v", err)
+		}
+	}
+
+	waitFor(t, func() bool { return d.offset() == 61 })
+	d.shutdown(time.Second)
+
+	for chatID, ids := range handled {
+		for i := 1; i < len(ids); i++ {
+			if ids[i] < ids[i-1] {
+				t.Errorf("updates of chat %d handled out of order: %v", chatID, ids)
+				break
+			}
+		}
+	}
+}
+
+func TestDispatcherOffsetWaitsForPendingUpdates(t *testing.T) {
+	release := make(chan struct{})
+	config := entity.UpdateConfig{OnUpdate: func(update entity.Update) {
+		if update.UpdateID == 5 {
+			<-release
+		}
+	}}
+
+	b := NewBot("token", BotOptions{Concurrency: 2}).(*bot)
+	d := newDispatcher(b, config)
+	defer d.shutdown(time.Second)
+
+	// chat 1 blocks on update 5 while chat 2 keeps going.
+	for _, update := range []entity.Update{chatUpdate(5, 1), chatUpdate(6, 2), chatUpdate(7, 2)} {
+		if _, err := d.dispatch(context.Background(), update); err != nil {
+			t.Fatalf("dispatch() error = %v", err)
+		}
+	}
+
+	waitFor(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(d.pending) == 1
+	})
+	if got := d.offset(); got != 5 {
+		t.Errorf("offset() = %d while update 5 is running, want 5", got)
+	}
+
+	close(release)
+	waitFor(t, func() bool { return d.offset() == 8 })
+}
+
+func TestDispatcherSkipsDispatchedUpdates(t *testing.T) {
+	d := newDispatcher(NewBot("token", BotOptions{}).(*bot), entity.UpdateConfig{})
+	defer d.shutdown(time.Second)
+
+	for _, tt := range []struct {
+		updateID int64
+		want     bool
+	}{{2, true}, {2, false}, {1, false}, {3, true}} {
+		if got, err := d.dispatch(context.Background(), chatUpdate(tt.updateID, 1)); got != tt.want || err != nil {
+			t.Errorf("dispatch(%d) = %v, %v; want %v", tt.updateID, got, err, tt.want)
+		}
+	}
+}
+
+func TestDispatcherRecoversFromPanics(t *testing.T) {
+	logger := &panicLogger{}
+	config := entity.UpdateConfig{OnUpdate: func(update entity.Update) {
+		panic("boom")
+	}}
+
+	b := NewBot("token", BotOptions{Logger: logger}).(*bot)
+	d := newDispatcher(b, config)
+	defer d.shutdown(time.Second)
+
+	_, _ = d.dispatch(context.Background(), chatUpdate(1, 1))
+	_, _ = d.dispatch(context.Background(), chatUpdate(2, 1))
+	waitFor(t, func() bool { return d.offset() == 3 })
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.errors) != 2 {
+		t.Errorf("logged %v, want two panics", logger.errors)
+	}
+}
+
+func TestDispatcherShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{})
+	config := entity.UpdateConfig{OnUpdate: func(entity.Update) {
+		close(started)
+		<-release
+	}}
+
+	d := newDispatcher(NewBot("token", BotOptions{}).(*bot), config)
+	_, _ = d.dispatch(context.Background(), chatUpdate(1, 1))
+	<-started
+
+	if d.shutdown(10 * time.Millisecond) {
+		t.Error("shutdown() = true while a handler is running")
+	}
+}
+
+// waitFor waits up to a few seconds for condition to hold.
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
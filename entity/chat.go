@@ -1,5 +1,13 @@
 package entity
 
+// Types of a chat.
+const (
+	ChatTypePrivate    = "private"
+	ChatTypeGroup      = "group"
+	ChatTypeSupergroup = "supergroup"
+	ChatTypeChannel    = "channel"
+)
+
 // Chat represents a chat.
 type Chat struct {
 	// ID is a unique identifier for this chat.
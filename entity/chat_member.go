@@ -0,0 +1,162 @@
+package entity
+
+// Statuses of a chat member.
+const (
+	ChatMemberOwner         = "creator"
+	ChatMemberAdministrator = "administrator"
+	ChatMemberMember        = "member"
+	ChatMemberRestricted    = "restricted"
+	ChatMemberLeft          = "left"
+	ChatMemberBanned        = "kicked"
+)
+
+// ChatMember contains information about one member of a chat.
+// It holds the fields of all kinds of chat members, which kind it is can be told by Status.
+type ChatMember struct {
+	// Status is the member's status in the chat.
+	// It can be one of “creator”, “administrator”, “member”, “restricted”, “left” or “kicked”.
+	//
+	// It is a required field.
+	Status string `json:"status,omitempty"`
+	// User is information about the user.
+	//
+	// It is a required field.
+	User *User `json:"user,omitempty"`
+	// IsAnonymous is true, if the user's presence in the chat is hidden.
+	IsAnonymous bool `json:"is_anonymous,omitempty"`
+	// CustomTitle is the custom title for this user.
+	CustomTitle string `json:"custom_title,omitempty"`
+	// IsMember is true, if the user is a member of the chat at the moment of the request.
+	// It is only meaningful for restricted users.
+	IsMember bool `json:"is_member,omitempty"`
+	// UntilDate is the date when restrictions will be lifted for this user; unix time.
+	// If 0, then the user is restricted or banned forever.
+	UntilDate int64 `json:"until_date,omitempty"`
+
+	// CanBeEdited is true, if the bot is allowed to edit administrator privileges of that user.
+	CanBeEdited bool `json:"can_be_edited,omitempty"`
+	// CanManageChat is true, if the administrator can access the chat event log, chat statistics,
+	// message statistics in channels, see channel members, see anonymous administrators in supergroups
+	// and ignore slow mode.
+	CanManageChat bool `json:"can_manage_chat,omitempty"`
+	// CanDeleteMessages is true, if the administrator can delete messages of other users.
+	CanDeleteMessages bool `json:"can_delete_messages,omitempty"`
+	// CanManageVideoChats is true, if the administrator can manage video chats.
+	CanManageVideoChats bool `json:"can_manage_video_chats,omitempty"`
+	// CanRestrictMembers is true, if the administrator can restrict, ban or unban chat members.
+	CanRestrictMembers bool `json:"can_restrict_members,omitempty"`
+	// CanPromoteMembers is true, if the administrator can add new administrators.
+	CanPromoteMembers bool `json:"can_promote_members,omitempty"`
+	// CanChangeInfo is true, if the user is allowed to change the chat title, photo and other settings.
+	CanChangeInfo bool `json:"can_change_info,omitempty"`
+	// CanInviteUsers is true, if the user is allowed to invite new users to the chat.
+	CanInviteUsers bool `json:"can_invite_users,omitempty"`
+	// CanPostMessages is true, if the administrator can post in the channel; channels only.
+	CanPostMessages bool `json:"can_post_messages,omitempty"`
+	// CanEditMessages is true, if the administrator can edit messages of other users
+	// and can pin messages; channels only.
+	CanEditMessages bool `json:"can_edit_messages,omitempty"`
+	// CanPinMessages is true, if the user is allowed to pin messages; groups and supergroups only.
+	CanPinMessages bool `json:"can_pin_messages,omitempty"`
+	// CanManageTopics is true, if the user is allowed to create, rename, close, and reopen forum topics;
+	// supergroups only.
+	CanManageTopics bool `json:"can_manage_topics,omitempty"`
+
+	// CanSendMessages is true, if the user is allowed to send text messages, contacts, locations and venues.
+	CanSendMessages bool `json:"can_send_messages,omitempty"`
+	// CanSendMediaMessages is true, if the user is allowed to send audios, documents, photos, videos,
+	// video notes and voice notes.
+	CanSendMediaMessages bool `json:"can_send_media_messages,omitempty"`
+	// CanSendPolls is true, if the user is allowed to send polls.
+	CanSendPolls bool `json:"can_send_polls,omitempty"`
+	// CanSendOtherMessages is true, if the user is allowed to send animations, games, stickers
+	// and use inline bots.
+	CanSendOtherMessages bool `json:"can_send_other_messages,omitempty"`
+	// CanAddWebPagePreviews is true, if the user is allowed to add web page previews to their messages.
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews,omitempty"`
+}
+
+// IsAdministrator reports whether the member is the owner or an administrator of the chat.
+func (m *ChatMember) IsAdministrator() bool {
+	return m.Status == ChatMemberOwner || m.Status == ChatMemberAdministrator
+}
+
+// ChatInviteLink represents an invite link for a chat.
+type ChatInviteLink struct {
+	// InviteLink is the invite link.
+	// If the link was created by another chat administrator, then the second part of the link will be replaced with “…”.
+	//
+	// It is a required field.
+	InviteLink string `json:"invite_link,omitempty"`
+	// Creator is the creator of the link.
+	//
+	// It is a required field.
+	Creator *User `json:"creator,omitempty"`
+	// CreatesJoinRequest is true, if users joining the chat via the link need to be approved by chat administrators.
+	CreatesJoinRequest bool `json:"creates_join_request,omitempty"`
+	// IsPrimary is true, if the link is primary.
+	IsPrimary bool `json:"is_primary,omitempty"`
+	// IsRevoked is true, if the link is revoked.
+	IsRevoked bool `json:"is_revoked,omitempty"`
+	// Name is the invite link name.
+	Name string `json:"name,omitempty"`
+	// ExpireDate is the point in time (unix timestamp) when the link will expire or has been expired.
+	ExpireDate int64 `json:"expire_date,omitempty"`
+	// MemberLimit is the maximum number of users that can be members of the chat simultaneously
+	// after joining the chat via this invite link; 1-99999.
+	MemberLimit int64 `json:"member_limit,omitempty"`
+	// PendingJoinRequestCount is the number of pending join requests created using this link.
+	PendingJoinRequestCount int64 `json:"pending_join_request_count,omitempty"`
+}
+
+// ChatMemberUpdated represents changes in the status of a chat member.
+type ChatMemberUpdated struct {
+	// Chat is the chat the user belongs to.
+	//
+	// It is a required field.
+	Chat *Chat `json:"chat,omitempty"`
+	// From is the performer of the action, which resulted in the change.
+	//
+	// It is a required field.
+	From *User `json:"from,omitempty"`
+	// Date is the date the change was done in unix time.
+	//
+	// It is a required field.
+	Date int64 `json:"date,omitempty"`
+	// OldChatMember is the previous information about the chat member.
+	//
+	// It is a required field.
+	OldChatMember *ChatMember `json:"old_chat_member,omitempty"`
+	// NewChatMember is the new information about the chat member.
+	//
+	// It is a required field.
+	NewChatMember *ChatMember `json:"new_chat_member,omitempty"`
+	// InviteLink is the chat invite link, which was used by the user to join the chat;
+	// for joining by invite link events only.
+	InviteLink *ChatInviteLink `json:"invite_link,omitempty"`
+}
+
+// ChatJoinRequest represents a join request sent to a chat.
+type ChatJoinRequest struct {
+	// Chat is the chat to which the request was sent.
+	//
+	// It is a required field.
+	Chat *Chat `json:"chat,omitempty"`
+	// From is the user that sent the join request.
+	//
+	// It is a required field.
+	From *User `json:"from,omitempty"`
+	// UserChatID is the identifier of a private chat with the user who sent the join request.
+	// The bot can use it to send messages until the join request is processed.
+	//
+	// It is a required field.
+	UserChatID int64 `json:"user_chat_id,omitempty"`
+	// Date is the date the request was sent in unix time.
+	//
+	// It is a required field.
+	Date int64 `json:"date,omitempty"`
+	// Bio is the bio of the user.
+	Bio string `json:"bio,omitempty"`
+	// InviteLink is the chat invite link that was used by the user to send the join request.
+	InviteLink *ChatInviteLink `json:"invite_link,omitempty"`
+}
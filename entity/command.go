@@ -7,8 +7,9 @@ import (
 
 // Commands is a wrapper around commands to be sent to the telegram server
 type Commands struct {
-	Commands     []Command `json:"commands"`
-	LanguageCode string    `json:"language_code"`
+	Commands     []Command        `json:"commands"`
+	Scope        *BotCommandScope `json:"scope,omitempty"`
+	LanguageCode string           `json:"language_code,omitempty"`
 }
 
 // Command holds the command object the telegram server sends
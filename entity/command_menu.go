@@ -0,0 +1,196 @@
+package entity
+
+// CommandMenu is a declarative definition of the commands of a bot over several scopes and languages.
+// It is expanded into one list of commands for every scope and language pair.
+//
+//	menu := entity.CommandMenu{
+//		Scopes:    []entity.BotCommandScope{{Type: entity.BotCommandScopeAllPrivateChats}, {Type: entity.BotCommandScopeAllChatAdministrators}},
+//		Languages: []string{"", "fr"},
+//		Commands: []entity.MenuCommand{
+//			{Command: "start", Descriptions: map[string]string{"": "start the bot", "fr": "démarrer le bot"}},
+//			{Command: "ban", Descriptions: map[string]string{"": "ban a user"},
+//				Scopes: []entity.BotCommandScope{{Type: entity.BotCommandScopeAllChatAdministrators}}},
+//		},
+//	}
+type CommandMenu struct {
+	// Scopes are the scopes the menu is published to.
+	// If empty, the menu is only published to the default scope.
+	Scopes []BotCommandScope `json:"scopes,omitempty"`
+	// Languages are the two-letter ISO 639-1 language codes the menu is published for.
+	// The empty language code is for users whose language has no dedicated commands.
+	// If empty, the menu is only published for the empty language code.
+	Languages []string `json:"languages,omitempty"`
+	// Commands are the commands of the menu in the order they are shown.
+	Commands []MenuCommand `json:"commands"`
+}
+
+// MenuCommand is a command of a CommandMenu.
+type MenuCommand struct {
+	// Command is the text of the command without the leading '/'.
+	Command string `json:"command"`
+	// Descriptions maps language codes to the description of the command in that language.
+	// The description of the empty language code is used for languages without a dedicated description.
+	// The command is left out of the languages it has no description for.
+	Descriptions map[string]string `json:"descriptions"`
+	// Scopes are the scopes of the menu the command is shown in.
+	// If empty, the command is shown in all the scopes of the menu.
+	Scopes []BotCommandScope `json:"scopes,omitempty"`
+}
+
+// Expand returns the commands of every scope and language pair of the menu.
+func (m CommandMenu) Expand() []Commands {
+	scopes, languages := m.scopes(), m.languages()
+
+	sets := make([]Commands, 0, len(scopes)*len(languages))
+	for _, scope := range scopes {
+		for _, language := range languages {
+			commands := []Command{}
+			for _, command := range m.Commands {
+				if !command.inScope(scope) {
+					continue
+				}
+
+				if description := command.Description(language); description != "" {
+					commands = append(commands, Command{
+						Command:     command.Command,
+						Description: description,
+					})
+				}
+			}
+
+			scope := scope
+			sets = append(sets, Commands{
+				Commands:     commands,
+				Scope:        &scope,
+				LanguageCode: language,
+			})
+		}
+	}
+
+	return sets
+}
+
+// NewCommandMenu merges the commands of scope and language pairs into a CommandMenu.
+// It is the reverse of CommandMenu.Expand.
+// Descriptions equal to the one of the empty language code and scopes covering the whole menu are left out.
+func NewCommandMenu(sets []Commands) CommandMenu {
+	var menu CommandMenu
+
+	for _, set := range sets {
+		scope := BotCommandScope{Type: BotCommandScopeDefault}
+		if set.Scope != nil {
+			scope = *set.Scope
+		}
+		if !containsScope(menu.Scopes, scope) {
+			menu.Scopes = append(menu.Scopes, scope)
+		}
+		if !containsLanguage(menu.Languages, set.LanguageCode) {
+			menu.Languages = append(menu.Languages, set.LanguageCode)
+		}
+
+		// a command missing from the previous sets is placed right after the command before it,
+		// so the order of every set is kept.
+		previous := -1
+		for _, command := range set.Commands {
+			i := indexOfCommand(menu.Commands, command.Command)
+			if i == -1 {
+				i = previous + 1
+				menu.Commands = append(menu.Commands, MenuCommand{})
+				copy(menu.Commands[i+1:], menu.Commands[i:])
+				menu.Commands[i] = MenuCommand{
+					Command:      command.Command,
+					Descriptions: map[string]string{},
+				}
+			}
+			previous = i
+
+			menu.Commands[i].Descriptions[set.LanguageCode] = command.Description
+			if !containsScope(menu.Commands[i].Scopes, scope) {
+				menu.Commands[i].Scopes = append(menu.Commands[i].Scopes, scope)
+			}
+		}
+	}
+
+	for i := range menu.Commands {
+		command := &menu.Commands[i]
+		if fallback, ok := command.Descriptions[""]; ok {
+			for language, description := range command.Descriptions {
+				if language != "" && description == fallback {
+					delete(command.Descriptions, language)
+				}
+			}
+		}
+		if len(command.Scopes) == len(menu.Scopes) {
+			command.Scopes = nil
+		}
+	}
+
+	if len(menu.Scopes) == 1 && menu.Scopes[0] == (BotCommandScope{Type: BotCommandScopeDefault}) {
+		menu.Scopes = nil
+	}
+	if len(menu.Languages) == 1 && menu.Languages[0] == "" {
+		menu.Languages = nil
+	}
+
+	return menu
+}
+
+// Description returns the description of the command in language.
+// It falls back to the description of the empty language code.
+func (c MenuCommand) Description(language string) string {
+	if description, ok := c.Descriptions[language]; ok {
+		return description
+	}
+
+	return c.Descriptions[""]
+}
+
+func (c MenuCommand) inScope(scope BotCommandScope) bool {
+	return len(c.Scopes) == 0 || containsScope(c.Scopes, scope)
+}
+
+func (m CommandMenu) scopes() []BotCommandScope {
+	if len(m.Scopes) == 0 {
+		return []BotCommandScope{{Type: BotCommandScopeDefault}}
+	}
+
+	return m.Scopes
+}
+
+func (m CommandMenu) languages() []string {
+	if len(m.Languages) == 0 {
+		return []string{""}
+	}
+
+	return m.Languages
+}
+
+func indexOfCommand(commands []MenuCommand, command string) int {
+	for i, c := range commands {
+		if c.Command == command {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func containsScope(scopes []BotCommandScope, scope BotCommandScope) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsLanguage(languages []string, language string) bool {
+	for _, l := range languages {
+		if l == language {
+			return true
+		}
+	}
+
+	return false
+}
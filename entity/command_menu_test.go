@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandMenuExpand(t *testing.T) {
+	private := BotCommandScope{Type: BotCommandScopeAllPrivateChats}
+	admins := BotCommandScope{Type: BotCommandScopeAllChatAdministrators}
+
+	menu := CommandMenu{
+		Scopes:    []BotCommandScope{private, admins},
+		Languages: []string{"", "fr"},
+		Commands: []MenuCommand{
+			{Command: "start", Descriptions: map[string]string{"": "start the bot", "fr": "démarrer le bot"}},
+			{Command: "ban", Descriptions: map[string]string{"": "ban a user"}, Scopes: []BotCommandScope{admins}},
+			{Command: "aide", Descriptions: map[string]string{"fr": "aide"}},
+		},
+	}
+
+	want := []Commands{
+		{Scope: &private, LanguageCode: "", Commands: []Command{{"start", "start the bot"}}},
+		{Scope: &private, LanguageCode: "fr", Commands: []Command{{"start", "démarrer le bot"}, {"aide", "aide"}}},
+		{Scope: &admins, LanguageCode: "", Commands: []Command{{"start", "start the bot"}, {"ban", "ban a user"}}},
+		{Scope: &admins, LanguageCode: "fr", Commands: []Command{{"start", "démarrer le bot"}, {"ban", "ban a user"}, {"aide", "aide"}}},
+	}
+
+	sets := menu.Expand()
+	if !reflect.DeepEqual(sets, want) {
+		t.Fatalf("Expand() = %+v; want %+v", sets, want)
+	}
+
+	if back := NewCommandMenu(sets); !reflect.DeepEqual(back, menu) {
+		t.Errorf("NewCommandMenu(Expand()) = %+v; want %+v", back, menu)
+	}
+}
+
+func TestCommandMenuDefaults(t *testing.T) {
+	menu := CommandMenu{Commands: []MenuCommand{{Command: "start", Descriptions: map[string]string{"": "start"}}}}
+
+	sets := menu.Expand()
+	if len(sets) != 1 || *sets[0].Scope != (BotCommandScope{Type: BotCommandScopeDefault}) || sets[0].LanguageCode != "" {
+		t.Fatalf("Expand() = %+v; want only the default scope and language", sets)
+	}
+
+	if back := NewCommandMenu(sets); !reflect.DeepEqual(back, menu) {
+		t.Errorf("NewCommandMenu(Expand()) = %+v; want %+v", back, menu)
+	}
+}
@@ -46,3 +46,31 @@ const (
 	// BotCommandScopeChatMember is the chat member scope.
 	BotCommandScopeChatMember BotCommandScopeType = "chat_member"
 )
+
+// Types of a MessageEntity.
+const (
+	EntityMention       = "mention"
+	EntityHashtag       = "hashtag"
+	EntityCashtag       = "cashtag"
+	EntityBotCommand    = "bot_command"
+	EntityURL           = "url"
+	EntityEmail         = "email"
+	EntityPhoneNumber   = "phone_number"
+	EntityBold          = "bold"
+	EntityItalic        = "italic"
+	EntityUnderline     = "underline"
+	EntityStrikethrough = "strikethrough"
+	EntitySpoiler       = "spoiler"
+	EntityCode          = "code"
+	EntityPre           = "pre"
+	EntityTextLink      = "text_link"
+	EntityTextMention   = "text_mention"
+	EntityCustomEmoji   = "custom_emoji"
+)
+
+// Modes for parsing entities in the text of a message.
+const (
+	ParseModeMarkdownV2 = "MarkdownV2"
+	ParseModeMarkdown   = "Markdown"
+	ParseModeHTML       = "HTML"
+)
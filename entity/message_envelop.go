@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"context"
 	"io"
 	"mime/multipart"
 	"os"
@@ -210,7 +211,14 @@ type FileEnvelop struct {
 	Name string
 }
 
+// SetValue writes the file to writer as a form field named name.
 func (f *FileEnvelop) SetValue(writer *multipart.Writer, name string) error {
+	return f.SetValueContext(context.Background(), writer, name)
+}
+
+// SetValueContext is the same as SetValue but stops copying a local file
+// as soon as ctx is done.
+func (f *FileEnvelop) SetValueContext(ctx context.Context, writer *multipart.Writer, name string) error {
 	if len(name) == 0 {
 		name = f.Name
 	}
@@ -231,10 +239,24 @@ func (f *FileEnvelop) SetValue(writer *multipart.Writer, name string) error {
 			return err
 		}
 
-		_, err = io.Copy(fileField, file)
+		_, err = io.Copy(fileField, &contextReader{ctx: ctx, reader: file})
 
 		return err
 	}
 
 	return writer.WriteField(name, f.Path)
 }
+
+// contextReader is an io.Reader that fails once its context is done.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.reader.Read(p)
+}
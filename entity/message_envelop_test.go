@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"context"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cancelWriter cancels a context once something is written to it.
+type cancelWriter struct {
+	cancel  context.CancelFunc
+	written int
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	w.written += len(p)
+	w.cancel()
+
+	return len(p), nil
+}
+
+func TestFileEnvelopSetValueContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.bin")
+	content := make([]byte, 4<<20)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := &cancelWriter{cancel: cancel}
+	err := (&FileEnvelop{Path: "file://" + path}).SetValueContext(ctx, multipart.NewWriter(out), "document")
+	if err != context.Canceled {
+		t.Fatalf("SetValueContext() error = %v, want %v", err, context.Canceled)
+	}
+	if out.written >= len(content) {
+		t.Errorf("the whole file was copied after the context was cancelled")
+	}
+}
+
+func TestFileEnvelopSetValue(t *testing.T) {
+	out := &cancelWriter{cancel: func() {}}
+	if err := (&FileEnvelop{Path: "file_id"}).SetValue(multipart.NewWriter(out), "photo"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+
+	err := (&FileEnvelop{Path: "file:///does/not/exist"}).SetValue(multipart.NewWriter(out), "photo")
+	if !os.IsNotExist(err) {
+		t.Errorf("SetValue() error = %v, want a missing file", err)
+	}
+}
@@ -9,3 +9,92 @@ type LabeledPrice struct {
 	// For example, for a price of US$ 1.45 pass amount = 145.
 	Amount int64 `json:"amount,omitempty"`
 }
+
+// ShippingAddress represents a shipping address.
+type ShippingAddress struct {
+	// CountryCode is the two-letter ISO 3166-1 alpha-2 country code.
+	//
+	// It is a required field.
+	CountryCode string `json:"country_code,omitempty"`
+	// State is the state, if applicable.
+	State string `json:"state,omitempty"`
+	// City is the city.
+	//
+	// It is a required field.
+	City string `json:"city,omitempty"`
+	// StreetLine1 is the first line of the address.
+	//
+	// It is a required field.
+	StreetLine1 string `json:"street_line1,omitempty"`
+	// StreetLine2 is the second line of the address.
+	//
+	// It is a required field.
+	StreetLine2 string `json:"street_line2,omitempty"`
+	// PostCode is the address post code.
+	//
+	// It is a required field.
+	PostCode string `json:"post_code,omitempty"`
+}
+
+// OrderInfo represents information about an order.
+type OrderInfo struct {
+	// Name is the user name.
+	Name string `json:"name,omitempty"`
+	// PhoneNumber is the user's phone number.
+	PhoneNumber string `json:"phone_number,omitempty"`
+	// Email is the user email.
+	Email string `json:"email,omitempty"`
+	// ShippingAddress is the user shipping address.
+	ShippingAddress *ShippingAddress `json:"shipping_address,omitempty"`
+}
+
+// ShippingQuery contains information about an incoming shipping query.
+// It is only sent for invoices with flexible price.
+type ShippingQuery struct {
+	// ID is the unique query identifier.
+	//
+	// It is a required field.
+	ID string `json:"id,omitempty"`
+	// From is the user who sent the query.
+	//
+	// It is a required field.
+	From *User `json:"from,omitempty"`
+	// InvoicePayload is the bot specified invoice payload.
+	//
+	// It is a required field.
+	InvoicePayload string `json:"invoice_payload,omitempty"`
+	// ShippingAddress is the user specified shipping address.
+	//
+	// It is a required field.
+	ShippingAddress *ShippingAddress `json:"shipping_address,omitempty"`
+}
+
+// PreCheckoutQuery contains information about an incoming pre-checkout query.
+// It must be answered within 10 seconds after it is sent.
+type PreCheckoutQuery struct {
+	// ID is the unique query identifier.
+	//
+	// It is a required field.
+	ID string `json:"id,omitempty"`
+	// From is the user who sent the query.
+	//
+	// It is a required field.
+	From *User `json:"from,omitempty"`
+	// Currency is the three-letter ISO 4217 currency code.
+	//
+	// It is a required field.
+	Currency string `json:"currency,omitempty"`
+	// TotalAmount is the total price in the smallest units of the currency
+	// (integer, not float/double).
+	//
+	// It is a required field.
+	TotalAmount int64 `json:"total_amount,omitempty"`
+	// InvoicePayload is the bot specified invoice payload.
+	//
+	// It is a required field.
+	InvoicePayload string `json:"invoice_payload,omitempty"`
+	// ShippingOptionID is the identifier of the shipping option chosen by the user.
+	ShippingOptionID string `json:"shipping_option_id,omitempty"`
+	// OrderInfo is the order information provided by the user.
+	OrderInfo *OrderInfo `json:"order_info,omitempty"`
+}
@@ -57,3 +57,20 @@ type PollOption struct {
 	// It is q required field
 	VoterCount int64 `json:"voter_count"`
 }
+
+// PollAnswer represents an answer of a user in a non-anonymous poll.
+type PollAnswer struct {
+	// PollID is the unique poll identifier.
+	//
+	// It is a required field
+	PollID string `json:"poll_id"`
+	// VoterChat is the chat that changed the answer to the poll, if the voter is anonymous.
+	VoterChat *Chat `json:"voter_chat,omitempty"`
+	// User is the user that changed the answer to the poll, if the voter isn't anonymous.
+	User *User `json:"user,omitempty"`
+	// OptionIDs is the 0-based identifiers of chosen answer options.
+	// It is empty if the vote was retracted.
+	//
+	// It is a required field
+	OptionIDs []int64 `json:"option_ids"`
+}
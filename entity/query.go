@@ -10,7 +10,7 @@ type InlineQuery struct {
 	// From is the sender of the query.
 	//
 	// It is a required field.
-	From *User `json:"user,omitempty"`
+	From *User `json:"from,omitempty"`
 	// Query is the text of the query.
 	//
 	// It is a required field.
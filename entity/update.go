@@ -24,7 +24,6 @@ const (
 
 // Update holds values from an update sent by the telegram server.
 // At most one of the optional parameters can be present in any given update.
-// // incomplete //
 type Update struct {
 	// UpdateID is the update's unique identifier.
 	//
@@ -44,13 +43,32 @@ type Update struct {
 	ChosenInlineResult *ChosenInlineResult `json:"chosen_inline_result,omitempty"`
 	// CallbackQuery is a new incoming callback query.
 	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+	// ShippingQuery is a new incoming shipping query.
+	// Only for invoices with flexible price.
+	ShippingQuery *ShippingQuery `json:"shipping_query,omitempty"`
+	// PreCheckoutQuery is a new incoming pre-checkout query. Contains full information about checkout.
+	PreCheckoutQuery *PreCheckoutQuery `json:"pre_checkout_query,omitempty"`
+	// Poll is a new poll state.
+	// Bots receive only updates about stopped polls and polls, which are sent by the bot.
+	Poll *Poll `json:"poll,omitempty"`
+	// PollAnswer is a user that changed their answer in a non-anonymous poll.
+	// Bots receive new votes only in polls that were sent by the bot itself.
+	PollAnswer *PollAnswer `json:"poll_answer,omitempty"`
+	// MyChatMember is the bot's chat member status that was updated in a chat.
+	MyChatMember *ChatMemberUpdated `json:"my_chat_member,omitempty"`
+	// ChatMember is a chat member's status that was updated in a chat.
+	// The bot must explicitly specify UpdateChatMember in the list of allowed updates to receive these updates.
+	ChatMember *ChatMemberUpdated `json:"chat_member,omitempty"`
+	// ChatJoinRequest is a request to join the chat that has been sent.
+	ChatJoinRequest *ChatJoinRequest `json:"chat_join_request,omitempty"`
 }
 
 // UpdateConfig holds methods for each kind of update
-// only one of the methods is called for an update.
-//
-// TODO: incomplete
+// only one of the methods is called for an update, besides OnUpdate.
 type UpdateConfig struct {
+	// OnUpdate is called for every update before the method of its kind.
+	// It can be used to plug in a router.
+	OnUpdate func(update Update)
 	// OnMessage is called if this update holds a new message.
 	OnMessage func(message Message)
 	// OnEditedMessage is called if this update holds an existing but edited message.
@@ -66,6 +84,90 @@ type UpdateConfig struct {
 	OnChosenInlineResult func(chosenInlineResult ChosenInlineResult)
 	// OnCallbackQuery is called if this update holds a new incoming callback query.
 	OnCallbackQuery func(callbackQuery CallbackQuery)
+	// OnShippingQuery is called if this update holds a new incoming shipping query.
+	OnShippingQuery func(shippingQuery ShippingQuery)
+	// OnPreCheckoutQuery is called if this update holds a new incoming pre-checkout query.
+	OnPreCheckoutQuery func(preCheckoutQuery PreCheckoutQuery)
+	// OnPoll is called if this update holds a new poll state.
+	OnPoll func(poll Poll)
+	// OnPollAnswer is called if this update holds a changed answer of a user in a non-anonymous poll.
+	OnPollAnswer func(pollAnswer PollAnswer)
+	// OnMyChatMember is called if this update holds a change of the bot's chat member status.
+	OnMyChatMember func(myChatMember ChatMemberUpdated)
+	// OnChatMember is called if this update holds a change of a chat member's status.
+	OnChatMember func(chatMember ChatMemberUpdated)
+	// OnChatJoinRequest is called if this update holds a new request to join a chat.
+	OnChatJoinRequest func(chatJoinRequest ChatJoinRequest)
+}
+
+// GetMessage returns the message this update holds if there is any.
+// It is one of Message, EditedMessage, ChannelPost, EditedChannelPost
+// or the message of CallbackQuery.
+func (u *Update) GetMessage() *Message {
+	switch {
+	case u.Message != nil:
+		return u.Message
+	case u.EditedMessage != nil:
+		return u.EditedMessage
+	case u.ChannelPost != nil:
+		return u.ChannelPost
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.Message
+	}
+
+	return nil
+}
+
+// GetChat returns the chat this update belongs to if there is any.
+func (u *Update) GetChat() *Chat {
+	switch {
+	case u.MyChatMember != nil:
+		return u.MyChatMember.Chat
+	case u.ChatMember != nil:
+		return u.ChatMember.Chat
+	case u.ChatJoinRequest != nil:
+		return u.ChatJoinRequest.Chat
+	case u.PollAnswer != nil && u.PollAnswer.VoterChat != nil:
+		return u.PollAnswer.VoterChat
+	}
+
+	if message := u.GetMessage(); message != nil {
+		return message.Chat
+	}
+
+	return nil
+}
+
+// GetFrom returns the user that caused this update if there is any.
+func (u *Update) GetFrom() *User {
+	switch {
+	case u.InlineQuery != nil:
+		return u.InlineQuery.From
+	case u.ChosenInlineResult != nil:
+		return u.ChosenInlineResult.From
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.From
+	case u.ShippingQuery != nil:
+		return u.ShippingQuery.From
+	case u.PreCheckoutQuery != nil:
+		return u.PreCheckoutQuery.From
+	case u.PollAnswer != nil:
+		return u.PollAnswer.User
+	case u.MyChatMember != nil:
+		return u.MyChatMember.From
+	case u.ChatMember != nil:
+		return u.ChatMember.From
+	case u.ChatJoinRequest != nil:
+		return u.ChatJoinRequest.From
+	}
+
+	if message := u.GetMessage(); message != nil {
+		return message.From
+	}
+
+	return nil
 }
 
 // FromJSONBody modifies the current Update with matching values in body
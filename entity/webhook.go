@@ -14,6 +14,10 @@ type Webhook struct {
 	//
 	// It is a required field
 	URL string `json:"url,omitempty"`
+	// Certificate is the public key certificate of a self-signed webhook server,
+	// so that the root certificate in use can be checked.
+	// It must be a local file (prefixed with `file://`) in PEM format.
+	Certificate *FileEnvelop `json:"certificate,omitempty"`
 	// IPAddress is a fixed IP address which will be used to send webhook requests
 	// instead of the IP address resolved through DNS.
 	IPAddress string `json:"ip_address,omitempty"`
@@ -32,6 +36,39 @@ type Webhook struct {
 	SecretToken string `json:"secret_token,omitempty"`
 }
 
+// WebhookInfo describes the current status of a webhook.
+type WebhookInfo struct {
+	// URL is the webhook url, may be empty if webhook is not set up.
+	//
+	// It is a required field
+	URL string `json:"url"`
+	// HasCustomCertificate is true, if a custom certificate was provided for webhook certificate checks.
+	//
+	// It is a required field
+	HasCustomCertificate bool `json:"has_custom_certificate"`
+	// PendingUpdateCount is the number of updates awaiting delivery.
+	//
+	// It is a required field
+	PendingUpdateCount int64 `json:"pending_update_count"`
+	// IPAddress is the currently used webhook IP address.
+	IPAddress string `json:"ip_address,omitempty"`
+	// LastErrorDate is the unix time for the most recent error
+	// that happened when trying to deliver an update via webhook.
+	LastErrorDate int64 `json:"last_error_date,omitempty"`
+	// LastErrorMessage is the error message in human-readable format for the most recent error
+	// that happened when trying to deliver an update via webhook.
+	LastErrorMessage string `json:"last_error_message,omitempty"`
+	// LastSynchronizationErrorDate is the unix time of the most recent error
+	// that happened when trying to synchronize available updates with Telegram datacenters.
+	LastSynchronizationErrorDate int64 `json:"last_synchronization_error_date,omitempty"`
+	// MaxConnections is the maximum allowed number of simultaneous HTTPS connections
+	// to the webhook for update delivery.
+	MaxConnections int64 `json:"max_connections,omitempty"`
+	// AllowedUpdates is a list of update types the bot is subscribed to.
+	// Defaults to all update types except chat_member.
+	AllowedUpdates []string `json:"allowed_updates,omitempty"`
+}
+
 // FromJSONBody modifies the current Webhook with matching values in body
 func (u *Webhook) FromJSONBody(body []byte) {
 	err := json.Unmarshal(body, u)
@@ -12,3 +12,12 @@ type DeleteMyCommandsEnvelop struct {
 	// for whose language there are no dedicated commands
 	LanguageCode string `json:"language_code,omitempty"`
 }
+
+// GetMyCommandsEnvelop represents a request to get the currently registered commands of a scope and language
+type GetMyCommandsEnvelop struct {
+	// Scope is the scope of the commands.
+	// Defaults to entity.BotCommandScopeDefault.
+	Scope *entity.BotCommandScope `json:"scope,omitempty"`
+	// LanguageCode is a two-letter ISO 639-1 language code or an empty string.
+	LanguageCode string `json:"language_code,omitempty"`
+}
@@ -9,3 +9,11 @@ type SetChatAdministratorCustomTitle struct {
 	// CustomTitle is the new custom title to set.
 	CustomTitle string `json:"custom_title,omitempty"`
 }
+
+// GetChatMember is the request body for getting information about a member of a chat
+type GetChatMember struct {
+	// ChatID of the target chat or username of the target supergroup or channel.
+	ChatID string `json:"chat_id,omitempty"`
+	// UserID of the target user.
+	UserID int64 `json:"user_id,omitempty"`
+}
@@ -0,0 +1,19 @@
+package envelop
+
+// GetUpdatesEnvelop is used to receive incoming updates using long polling.
+type GetUpdatesEnvelop struct {
+	// Offset is the identifier of the first update to be returned.
+	// It must be greater by one than the highest among the identifiers of previously received updates.
+	// All updates with a lower identifier are considered confirmed.
+	Offset int64 `json:"offset,omitempty"`
+	// Limit limits the number of updates to be retrieved.
+	// Values between 1-100 are accepted. Defaults to 100.
+	Limit int64 `json:"limit,omitempty"`
+	// Timeout is the timeout in seconds for long polling.
+	// Defaults to 0, i.e. usual short polling.
+	Timeout int64 `json:"timeout,omitempty"`
+	// AllowedUpdates is the list of the update types you want your bot to receive.
+	//
+	// eg: entity.UpdateMessage, entity.UpdateEditedMessage, etc
+	AllowedUpdates []string `json:"allowed_updates,omitempty"`
+}
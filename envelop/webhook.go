@@ -0,0 +1,7 @@
+package envelop
+
+// DeleteWebhookEnvelop is used to remove webhook integration.
+type DeleteWebhookEnvelop struct {
+	// DropPendingUpdates can be true to drop all pending updates.
+	DropPendingUpdates bool `json:"drop_pending_updates,omitempty"`
+}
@@ -0,0 +1,151 @@
+package gotbot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// Common telegram failure classes.
+// They can be matched against errors returned by the Bot using errors.Is.
+//
+//	if errors.Is(err, gotbot.ErrBotBlocked) {
+//		// stop sending messages to this user
+//	}
+var (
+	// ErrUnauthorized is returned when the bot token is invalid.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrBotBlocked is returned when the user has blocked the bot.
+	ErrBotBlocked = errors.New("bot was blocked by the user")
+	// ErrBotKicked is returned when the bot was removed from the target group or channel.
+	ErrBotKicked = errors.New("bot was kicked from the chat")
+	// ErrUserDeactivated is returned when the target user account was deleted.
+	ErrUserDeactivated = errors.New("user is deactivated")
+	// ErrChatNotFound is returned when the target chat doesn't exist or the bot has no access to it.
+	ErrChatNotFound = errors.New("chat not found")
+	// ErrMessageNotFound is returned when the message to edit, delete or reply to doesn't exist.
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrMessageNotModified is returned when an edit leaves the message exactly as it was.
+	ErrMessageNotModified = errors.New("message is not modified")
+	// ErrChatMigrated is returned when a group was upgraded to a supergroup.
+	// The new chat id is available on APIError.MigrateToChatID.
+	ErrChatMigrated = errors.New("chat migrated to a supergroup")
+	// ErrTooManyRequests is returned when the flood limit is exceeded.
+	// The time to wait is available on APIError.RetryAfter.
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrConflict is returned when another getUpdates request or an active webhook conflicts with this request.
+	ErrConflict = errors.New("conflict")
+)
+
+// APIError is returned when the telegram server responds with `ok` set to false.
+type APIError struct {
+	// ErrorCode is the error code sent by the server.
+	// Its value mostly matches the http status code of the response.
+	ErrorCode int64
+	// Description is a human-readable description of the error.
+	Description string
+	// RetryAfter is, in case of exceeding flood control, the number of seconds
+	// left to wait before the request can be repeated.
+	RetryAfter int64
+	// MigrateToChatID is, if the group has been migrated to a supergroup,
+	// the identifier of the new supergroup.
+	MigrateToChatID int64
+}
+
+// newAPIError creates an APIError from a failed response.
+func newAPIError(res entity.Response) *APIError {
+	apiErr := &APIError{
+		ErrorCode:   res.ErrorCode,
+		Description: res.Description,
+	}
+
+	if res.Parameters != nil {
+		apiErr.RetryAfter = res.Parameters.RetryAfter
+		apiErr.MigrateToChatID = res.Parameters.MigrateToChatID
+	}
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("error response: code = %d, description = %s", e.ErrorCode, e.Description)
+	if e.RetryAfter != 0 {
+		msg += fmt.Sprintf(", retry after = %ds", e.RetryAfter)
+	}
+	if e.MigrateToChatID != 0 {
+		msg += fmt.Sprintf(", migrate to chat id = %d", e.MigrateToChatID)
+	}
+
+	return msg
+}
+
+// Is reports whether this error belongs to the failure class of target.
+// target should be one of the Err* values of this package.
+func (e *APIError) Is(target error) bool {
+	description := strings.ToLower(e.Description)
+
+	switch target {
+	case ErrUnauthorized:
+		return e.ErrorCode == http.StatusUnauthorized
+	case ErrBotBlocked:
+		return e.ErrorCode == http.StatusForbidden && strings.Contains(description, "bot was blocked")
+	case ErrBotKicked:
+		return e.ErrorCode == http.StatusForbidden && strings.Contains(description, "bot was kicked")
+	case ErrUserDeactivated:
+		return e.ErrorCode == http.StatusForbidden && strings.Contains(description, "user is deactivated")
+	case ErrChatNotFound:
+		return strings.Contains(description, "chat not found")
+	case ErrMessageNotFound:
+		return strings.Contains(description, "message to edit not found") ||
+			strings.Contains(description, "message to delete not found") ||
+			strings.Contains(description, "message to reply not found") ||
+			strings.Contains(description, "message to forward not found") ||
+			strings.Contains(description, "message to copy not found")
+	case ErrMessageNotModified:
+		return strings.Contains(description, "message is not modified")
+	case ErrChatMigrated:
+		return e.MigrateToChatID != 0
+	case ErrTooManyRequests:
+		return e.ErrorCode == http.StatusTooManyRequests
+	case ErrConflict:
+		return e.ErrorCode == http.StatusConflict
+	}
+
+	return false
+}
+
+// AsAPIError returns the APIError wrapped in err if there is any.
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+
+	return nil, false
+}
+
+// RetryAfter returns how long to wait before repeating a request that failed with err.
+// The second return value is false if err doesn't carry a retry_after parameter.
+func RetryAfter(err error) (time.Duration, bool) {
+	apiErr, ok := AsAPIError(err)
+	if !ok || apiErr.RetryAfter == 0 {
+		return 0, false
+	}
+
+	return time.Duration(apiErr.RetryAfter) * time.Second, true
+}
+
+// MigratedChatID returns the id of the supergroup a group was migrated to
+// if err was caused by such a migration.
+func MigratedChatID(err error) (int64, bool) {
+	apiErr, ok := AsAPIError(err)
+	if !ok || apiErr.MigrateToChatID == 0 {
+		return 0, false
+	}
+
+	return apiErr.MigrateToChatID, true
+}
@@ -0,0 +1,52 @@
+package gotbot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		err    *APIError
+		target error
+	}{
+		{&APIError{ErrorCode: 401, Description: "Unauthorized"}, ErrUnauthorized},
+		{&APIError{ErrorCode: 403, Description: "Forbidden: bot was blocked by the user"}, ErrBotBlocked},
+		{&APIError{ErrorCode: 403, Description: "Forbidden: bot was kicked from the group chat"}, ErrBotKicked},
+		{&APIError{ErrorCode: 403, Description: "Forbidden: user is deactivated"}, ErrUserDeactivated},
+		{&APIError{ErrorCode: 400, Description: "Bad Request: chat not found"}, ErrChatNotFound},
+		{&APIError{ErrorCode: 400, Description: "Bad Request: message to edit not found"}, ErrMessageNotFound},
+		{&APIError{ErrorCode: 400, Description: "Bad Request: message is not modified"}, ErrMessageNotModified},
+		{&APIError{ErrorCode: 400, Description: "Bad Request: group chat was upgraded to a supergroup chat", MigrateToChatID: -100}, ErrChatMigrated},
+		{&APIError{ErrorCode: 429, Description: "Too Many Requests: retry after 5", RetryAfter: 5}, ErrTooManyRequests},
+		{&APIError{ErrorCode: 409, Description: "Conflict: terminated by other getUpdates request"}, ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target.Error(), func(t *testing.T) {
+			err := fmt.Errorf("send: %w", tt.err)
+			if !errors.Is(err, tt.target) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.target)
+			}
+			if errors.Is(err, ErrUnauthorized) != (tt.target == ErrUnauthorized) {
+				t.Errorf("errors.Is(%v, ErrUnauthorized) = %v", err, !(tt.target == ErrUnauthorized))
+			}
+		})
+	}
+}
+
+func TestRetryAfterAndMigratedChatID(t *testing.T) {
+	err := fmt.Errorf("send: %w", &APIError{ErrorCode: 429, RetryAfter: 7, MigrateToChatID: -1001})
+
+	if d, ok := RetryAfter(err); !ok || d != 7*time.Second {
+		t.Errorf("RetryAfter() = %v, %v; want 7s, true", d, ok)
+	}
+	if id, ok := MigratedChatID(err); !ok || id != -1001 {
+		t.Errorf("MigratedChatID() = %v, %v; want -1001, true", id, ok)
+	}
+	if _, ok := RetryAfter(errors.New("other")); ok {
+		t.Error("RetryAfter() of a plain error is ok")
+	}
+}
@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/envelop"
+)
+
+// DefaultAdminTTL is how long Admin remembers the status of a chat member.
+const DefaultAdminTTL = time.Minute
+
+// Admin matches updates sent by the owner or an administrator of the chat of the update.
+// The status of the sender is fetched with api and remembered for DefaultAdminTTL.
+func Admin(api ChatMemberGetter) Filter {
+	return AdminWithTTL(api, DefaultAdminTTL)
+}
+
+// AdminWithTTL is the same as Admin but remembers the status of a chat member for ttl.
+// Failed requests are not remembered.
+func AdminWithTTL(api ChatMemberGetter, ttl time.Duration) Filter {
+	cache := newMemberCache(ttl)
+
+	return func(update entity.Update) bool {
+		chat, user := update.GetChat(), update.GetFrom()
+		if chat == nil || user == nil {
+			return false
+		}
+
+		key := memberKey{chatID: chat.ID, userID: user.ID}
+		if admin, ok := cache.get(key); ok {
+			return admin
+		}
+
+		member, err := api.GetChatMember(envelop.GetChatMember{
+			ChatID: strconv.FormatInt(chat.ID, 10),
+			UserID: user.ID,
+		})
+		if err != nil {
+			return false
+		}
+
+		admin := member.IsAdministrator()
+		cache.set(key, admin)
+
+		return admin
+	}
+}
+
+// now is replaced in tests.
+var now = time.Now
+
+type memberKey struct {
+	chatID, userID int64
+}
+
+type memberStatus struct {
+	admin     bool
+	expiresAt time.Time
+}
+
+// memberCache remembers whether chat members are administrators.
+// Expired entries are removed at most once per ttl.
+type memberCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	members  map[memberKey]memberStatus
+	purgedAt time.Time
+}
+
+func newMemberCache(ttl time.Duration) *memberCache {
+	return &memberCache{ttl: ttl, members: map[memberKey]memberStatus{}}
+}
+
+func (c *memberCache) get(key memberKey) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status, ok := c.members[key]
+	if !ok || !now().Before(status.expiresAt) {
+		return false, false
+	}
+
+	return status.admin, true
+}
+
+func (c *memberCache) set(key memberKey, admin bool) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := now()
+	if current.Sub(c.purgedAt) >= c.ttl {
+		c.purgedAt = current
+		for k, status := range c.members {
+			if !current.Before(status.expiresAt) {
+				delete(c.members, k)
+			}
+		}
+	}
+
+	c.members[key] = memberStatus{admin: admin, expiresAt: current.Add(c.ttl)}
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/envelop"
+)
+
+type fakeMembers struct {
+	status string
+	err    error
+	calls  int
+}
+
+func (f *fakeMembers) GetChatMember(envelop.GetChatMember) (entity.ChatMember, error) {
+	f.calls++
+
+	return entity.ChatMember{Status: f.status}, f.err
+}
+
+func TestAdminCachesStatus(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	api := &fakeMembers{status: entity.ChatMemberAdministrator}
+	admin := AdminWithTTL(api, time.Minute)
+	update := message(entity.Message{})
+
+	for i := 0; i < 3; i++ {
+		if !admin(update) {
+			t.Fatal("Admin() = false for an administrator")
+		}
+	}
+	if api.calls != 1 {
+		t.Errorf("GetChatMember was called %d times within the ttl; want once", api.calls)
+	}
+
+	api.status = entity.ChatMemberMember
+	current = current.Add(time.Minute)
+	if admin(update) {
+		t.Error("Admin() = true after the member was demoted and the ttl expired")
+	}
+	if api.calls != 2 {
+		t.Errorf("GetChatMember was called %d times; want twice", api.calls)
+	}
+}
+
+func TestAdminDoesNotCacheErrors(t *testing.T) {
+	api := &fakeMembers{err: errors.New("too many requests")}
+	admin := Admin(api)
+	update := message(entity.Message{})
+
+	if admin(update) {
+		t.Fatal("Admin() = true on error")
+	}
+
+	api.err, api.status = nil, entity.ChatMemberOwner
+	if !admin(update) {
+		t.Error("Admin() = false for the owner after a failed request")
+	}
+}
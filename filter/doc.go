@@ -0,0 +1,3 @@
+// Package filter contains predicates over updates that decide whether a handler should handle an update.
+// Filters can be combined with And, Or and Not and attached to the handlers of a router.
+package filter
@@ -0,0 +1,243 @@
+package filter
+
+import (
+	"regexp"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/envelop"
+)
+
+// Filter reports whether update should be handled.
+type Filter func(update entity.Update) bool
+
+// Media is a kind of media a message can hold.
+type Media string
+
+// Kinds of media of a message.
+const (
+	MediaAnimation Media = "animation"
+	MediaAudio     Media = "audio"
+	MediaDocument  Media = "document"
+	MediaPhoto     Media = "photo"
+	MediaSticker   Media = "sticker"
+	MediaVideo     Media = "video"
+	MediaVideoNote Media = "video_note"
+	MediaVoice     Media = "voice"
+)
+
+// ChatMemberGetter is the part of the bot used by Admin.
+// It is implemented by gotbot.Bot.
+type ChatMemberGetter interface {
+	GetChatMember(options envelop.GetChatMember) (entity.ChatMember, error)
+}
+
+// All matches every update.
+func All() Filter {
+	return func(update entity.Update) bool {
+		return true
+	}
+}
+
+// And matches updates matched by all filters.
+func And(filters ...Filter) Filter {
+	return func(update entity.Update) bool {
+		for _, filter := range filters {
+			if !filter(update) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Or matches updates matched by at least one of filters.
+func Or(filters ...Filter) Filter {
+	return func(update entity.Update) bool {
+		for _, filter := range filters {
+			if filter(update) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not matches updates not matched by filter.
+func Not(filter Filter) Filter {
+	return func(update entity.Update) bool {
+		return !filter(update)
+	}
+}
+
+// Message matches updates that hold a new message or channel post.
+func Message() Filter {
+	return func(update entity.Update) bool {
+		return update.Message != nil || update.ChannelPost != nil
+	}
+}
+
+// ChatType matches updates of chats with one of the given types.
+// types are any of entity.ChatTypePrivate, entity.ChatTypeGroup, entity.ChatTypeSupergroup and entity.ChatTypeChannel.
+func ChatType(types ...string) Filter {
+	return func(update entity.Update) bool {
+		chat := update.GetChat()
+		if chat == nil {
+			return false
+		}
+
+		for _, t := range types {
+			if chat.Type == t {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Private matches updates of private chats.
+func Private() Filter {
+	return ChatType(entity.ChatTypePrivate)
+}
+
+// Group matches updates of groups and supergroups.
+func Group() Filter {
+	return ChatType(entity.ChatTypeGroup, entity.ChatTypeSupergroup)
+}
+
+// Channel matches updates of channels.
+func Channel() Filter {
+	return ChatType(entity.ChatTypeChannel)
+}
+
+// Users matches updates sent by one of the users with the given ids.
+func Users(ids ...int64) Filter {
+	allowed := idSet(ids)
+
+	return func(update entity.Update) bool {
+		user := update.GetFrom()
+
+		return user != nil && allowed[user.ID]
+	}
+}
+
+// Chats matches updates of one of the chats with the given ids.
+func Chats(ids ...int64) Filter {
+	allowed := idSet(ids)
+
+	return func(update entity.Update) bool {
+		chat := update.GetChat()
+
+		return chat != nil && allowed[chat.ID]
+	}
+}
+
+// HasMedia matches messages that hold one of the given kinds of media.
+// If no kinds are given, it matches messages that hold any media.
+func HasMedia(kinds ...Media) Filter {
+	if len(kinds) == 0 {
+		kinds = []Media{MediaAnimation, MediaAudio, MediaDocument, MediaPhoto,
+			MediaSticker, MediaVideo, MediaVideoNote, MediaVoice}
+	}
+
+	return func(update entity.Update) bool {
+		message := messageOf(update)
+		if message == nil {
+			return false
+		}
+
+		for _, kind := range kinds {
+			if hasMedia(message, kind) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func hasMedia(message *entity.Message, kind Media) bool {
+	switch kind {
+	case MediaAnimation:
+		return message.Animation != nil
+	case MediaAudio:
+		return message.Audio != nil
+	case MediaDocument:
+		return message.Document != nil
+	case MediaPhoto:
+		return len(message.Photo) != 0
+	case MediaSticker:
+		return message.Sticker != nil
+	case MediaVideo:
+		return message.Video != nil
+	case MediaVideoNote:
+		return message.VideoNote != nil
+	case MediaVoice:
+		return message.Voice != nil
+	}
+
+	return false
+}
+
+// Text matches messages with text or a caption matched by re.
+func Text(re *regexp.Regexp) Filter {
+	return func(update entity.Update) bool {
+		message := messageOf(update)
+		if message == nil {
+			return false
+		}
+
+		text := message.Text
+		if text == "" {
+			text = message.Caption
+		}
+
+		return text != "" && re.MatchString(text)
+	}
+}
+
+// ReplyTo matches messages that reply to a message sent by the user with the id userID.
+func ReplyTo(userID int64) Filter {
+	return func(update entity.Update) bool {
+		message := messageOf(update)
+
+		return message != nil && message.ReplyToMessage != nil &&
+			message.ReplyToMessage.From != nil && message.ReplyToMessage.From.ID == userID
+	}
+}
+
+// ReplyToBot matches messages that reply to a message of the bot.
+// me is the bot as returned by GetMe.
+func ReplyToBot(me entity.User) Filter {
+	return ReplyTo(me.ID)
+}
+
+// Forwarded matches forwarded messages.
+func Forwarded() Filter {
+	return func(update entity.Update) bool {
+		message := messageOf(update)
+
+		return message != nil && message.ForwardDate != 0
+	}
+}
+
+// messageOf returns the message of update.
+// Unlike Update.GetMessage, the message a callback query was sent from is not returned.
+func messageOf(update entity.Update) *entity.Message {
+	if update.CallbackQuery != nil {
+		return nil
+	}
+
+	return update.GetMessage()
+}
+
+func idSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return set
+}
@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func message(m entity.Message) entity.Update {
+	if m.Chat == nil {
+		m.Chat = &entity.Chat{ID: 10, Type: entity.ChatTypePrivate}
+	}
+	if m.From == nil {
+		m.From = &entity.User{ID: 1}
+	}
+
+	return entity.Update{Message: &m}
+}
+
+func TestFilters(t *testing.T) {
+	group := &entity.Chat{ID: -20, Type: entity.ChatTypeSupergroup}
+	callback := entity.Update{CallbackQuery: &entity.CallbackQuery{
+		From:    &entity.User{ID: 1},
+		Message: &entity.Message{Text: "hello", Chat: group},
+	}}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		update entity.Update
+		want   bool
+	}{
+		{name: "all", filter: All(), update: entity.Update{}, want: true},
+		{name: "and", filter: And(Private(), Users(1)), update: message(entity.Message{}), want: true},
+		{name: "and fails", filter: And(Private(), Users(2)), update: message(entity.Message{})},
+		{name: "empty and", filter: And(), update: entity.Update{}, want: true},
+		{name: "or", filter: Or(Users(2), Chats(10)), update: message(entity.Message{}), want: true},
+		{name: "empty or", filter: Or(), update: entity.Update{}},
+		{name: "not", filter: Not(Group()), update: message(entity.Message{}), want: true},
+		{name: "message", filter: Message(), update: message(entity.Message{}), want: true},
+		{name: "callback is not a message", filter: Message(), update: callback},
+		{name: "group of callback", filter: Group(), update: callback, want: true},
+		{name: "channel", filter: Channel(), update: entity.Update{ChannelPost: &entity.Message{Chat: &entity.Chat{Type: entity.ChatTypeChannel}}}, want: true},
+		{name: "photo", filter: HasMedia(MediaPhoto), update: message(entity.Message{Photo: []entity.PhotoSize{{}}}), want: true},
+		{name: "any media", filter: HasMedia(), update: message(entity.Message{Voice: &entity.Voice{}}), want: true},
+		{name: "no media", filter: HasMedia(), update: message(entity.Message{Text: "hi"})},
+		{name: "text", filter: Text(regexp.MustCompile(`(?i)^hello`)), update: message(entity.Message{Text: "Hello there"}), want: true},
+		{name: "caption", filter: Text(regexp.MustCompile(`cat`)), update: message(entity.Message{Caption: "a cat"}), want: true},
+		{name: "text of callback", filter: Text(regexp.MustCompile(`hello`)), update: callback},
+		{
+			name:   "reply to bot",
+			filter: ReplyToBot(entity.User{ID: 99}),
+			update: message(entity.Message{ReplyToMessage: &entity.Message{From: &entity.User{ID: 99}}}),
+			want:   true,
+		},
+		{name: "reply to another user", filter: ReplyTo(99), update: message(entity.Message{ReplyToMessage: &entity.Message{From: &entity.User{ID: 5}}})},
+		{name: "forwarded", filter: Forwarded(), update: message(entity.Message{ForwardDate: 1}), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter(tt.update); got != tt.want {
+				t.Errorf("filter() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
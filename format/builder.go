@@ -0,0 +1,155 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// Builder builds a text along with its message entities.
+// The zero value is an empty builder ready to use.
+//
+//	text, entities := new(format.Builder).
+//		Text("Order ").Bold("#42").Text(" is ready 🎉\n").
+//		Link("track it", "https://example.com/42").
+//		Build()
+type Builder struct {
+	text     strings.Builder
+	length   int64
+	entities []entity.MessageEntity
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Len returns the length of the text built so far in UTF-16 code units.
+func (b *Builder) Len() int64 {
+	return b.length
+}
+
+// Text appends s without formatting.
+func (b *Builder) Text(s string) *Builder {
+	b.text.WriteString(s)
+	b.length += Len(s)
+
+	return b
+}
+
+// Bold appends s in bold.
+func (b *Builder) Bold(s string) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntityBold}, s)
+}
+
+// Italic appends s in italic.
+func (b *Builder) Italic(s string) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntityItalic}, s)
+}
+
+// Underline appends s underlined.
+func (b *Builder) Underline(s string) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntityUnderline}, s)
+}
+
+// Strikethrough appends s struck through.
+func (b *Builder) Strikethrough(s string) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntityStrikethrough}, s)
+}
+
+// Spoiler appends s hidden as a spoiler.
+func (b *Builder) Spoiler(s string) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntitySpoiler}, s)
+}
+
+// Code appends s as inline monowidth code.
+func (b *Builder) Code(s string) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntityCode}, s)
+}
+
+// Pre appends s as a monowidth code block.
+// language is the programming language of the code and can be empty.
+func (b *Builder) Pre(s, language string) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntityPre, Language: language}, s)
+}
+
+// Link appends s as a link to url.
+func (b *Builder) Link(s, url string) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntityTextLink, URL: url}, s)
+}
+
+// Mention appends s as a mention of user.
+// It works for users without a username.
+func (b *Builder) Mention(s string, user entity.User) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntityTextMention, User: &user}, s)
+}
+
+// CustomEmoji appends the custom emoji with the id customEmojiID.
+// emoji is a regular emoji shown where custom emoji aren't supported.
+func (b *Builder) CustomEmoji(emoji, customEmojiID string) *Builder {
+	return b.Format(entity.MessageEntity{Type: entity.EntityCustomEmoji, CustomEmojiID: customEmojiID}, emoji)
+}
+
+// Format appends s formatted with e. The offset and length of e are set by the builder.
+func (b *Builder) Format(e entity.MessageEntity, s string) *Builder {
+	return b.Nest(e, func(b *Builder) {
+		b.Text(s)
+	})
+}
+
+// Nest formats everything build appends with e, so formatting can be combined:
+//
+//	b.Nest(entity.MessageEntity{Type: entity.EntityBold}, func(b *format.Builder) {
+//		b.Text("bold and ").Italic("italic")
+//	})
+//
+// The offset and length of e are set by the builder.
+// Nothing is added if build appends no text.
+func (b *Builder) Nest(e entity.MessageEntity, build func(b *Builder)) *Builder {
+	index := len(b.entities)
+	e.Offset = b.length
+	b.entities = append(b.entities, e)
+
+	build(b)
+
+	b.entities[index].Length = b.length - e.Offset
+	if b.entities[index].Length == 0 {
+		b.entities = append(b.entities[:index], b.entities[index+1:]...)
+	}
+
+	return b
+}
+
+// Build returns the text and its entities.
+func (b *Builder) Build() (string, []entity.MessageEntity) {
+	entities := make([]entity.MessageEntity, len(b.entities))
+	copy(entities, b.entities)
+
+	return b.text.String(), entities
+}
+
+// Message returns a message to chatID with the built text.
+func (b *Builder) Message(chatID string) entity.MessageEnvelop {
+	text, entities := b.Build()
+
+	return entity.MessageEnvelop{
+		ChatID:   chatID,
+		Text:     text,
+		Entities: entities,
+	}
+}
+
+// Len returns the length of s in UTF-16 code units, the unit of the offsets of message entities.
+func Len(s string) int64 {
+	var length int64
+	for _, r := range s {
+		// runes outside the basic multilingual plane take a surrogate pair.
+		if r >= 0x10000 {
+			length += 2
+		} else {
+			length++
+		}
+	}
+
+	return length
+}
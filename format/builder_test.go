@@ -0,0 +1,109 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestLen(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int64
+	}{
+		{s: "", want: 0},
+		{s: "abc", want: 3},
+		{s: "é", want: 1},
+		{s: "ሰላም", want: 3},
+		{s: "🎉", want: 2},
+		{s: "a🎉b", want: 4},
+		{s: "👍🏽", want: 4},
+	}
+
+	for _, tt := range tests {
+		if got := Len(tt.s); got != tt.want {
+			t.Errorf("Len(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	user := entity.User{ID: 7}
+
+	tests := []struct {
+		name         string
+		build        func(b *Builder)
+		wantText     string
+		wantEntities []entity.MessageEntity
+	}{
+		{
+			name: "offsets after emoji",
+			build: func(b *Builder) {
+				b.Text("🎉 ").Bold("bold").Text(" ").Link("ሰላም", "https://example.com")
+			},
+			wantText: "🎉 bold ሰላም",
+			wantEntities: []entity.MessageEntity{
+				{Type: entity.EntityBold, Offset: 3, Length: 4},
+				{Type: entity.EntityTextLink, Offset: 8, Length: 3, URL: "https://example.com"},
+			},
+		},
+		{
+			name: "nested",
+			build: func(b *Builder) {
+				b.Nest(entity.MessageEntity{Type: entity.EntityBold}, func(b *Builder) {
+					b.Text("a ").Italic("😀b")
+				}).Pre("x := 1", "go")
+			},
+			wantText: "a 😀bx := 1",
+			wantEntities: []entity.MessageEntity{
+				{Type: entity.EntityBold, Offset: 0, Length: 5},
+				{Type: entity.EntityItalic, Offset: 2, Length: 3},
+				{Type: entity.EntityPre, Offset: 5, Length: 6, Language: "go"},
+			},
+		},
+		{
+			name: "empty entities are dropped",
+			build: func(b *Builder) {
+				b.Bold("").Nest(entity.MessageEntity{Type: entity.EntityItalic}, func(b *Builder) {
+					b.Underline("")
+				}).Mention("me", user)
+			},
+			wantText: "me",
+			wantEntities: []entity.MessageEntity{
+				{Type: entity.EntityTextMention, Offset: 0, Length: 2, User: &user},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuilder()
+			tt.build(b)
+
+			text, entities := b.Build()
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+			if len(entities) == 0 {
+				entities = nil
+			}
+			if !reflect.DeepEqual(entities, tt.wantEntities) {
+				t.Errorf("entities = %+v, want %+v", entities, tt.wantEntities)
+			}
+			if b.Len() != Len(tt.wantText) {
+				t.Errorf("Len() = %d, want %d", b.Len(), Len(tt.wantText))
+			}
+		})
+	}
+}
+
+func TestBuildCopiesEntities(t *testing.T) {
+	b := NewBuilder().Bold("a")
+	_, entities := b.Build()
+	entities[0].Type = entity.EntityItalic
+
+	if _, again := b.Build(); again[0].Type != entity.EntityBold {
+		t.Errorf("Build() shares its entities with the builder")
+	}
+}
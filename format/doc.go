@@ -0,0 +1,6 @@
+// Package format builds formatted text as plain text with message entities,
+// so no escaping for a parse mode is needed, and parses formatted messages back into segments.
+//
+// The offsets and lengths of message entities are counted in UTF-16 code units,
+// which this package takes care of.
+package format
@@ -0,0 +1,104 @@
+package format
+
+import (
+	"sort"
+	"unicode/utf16"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// Segment is a run of text with the same formatting.
+type Segment struct {
+	// Text is the text of the segment.
+	Text string
+	// Entities are the entities covering the segment, outermost first.
+	// Their offsets and lengths are the ones in the whole text.
+	Entities []entity.MessageEntity
+}
+
+// Has reports whether the segment is formatted with an entity of entityType.
+func (s Segment) Has(entityType string) bool {
+	_, ok := s.Entity(entityType)
+
+	return ok
+}
+
+// Entity returns the entity of entityType covering the segment.
+// The second return value is false if there is no such entity.
+func (s Segment) Entity(entityType string) (entity.MessageEntity, bool) {
+	for _, e := range s.Entities {
+		if e.Type == entityType {
+			return e, true
+		}
+	}
+
+	return entity.MessageEntity{}, false
+}
+
+// Parse splits the text or, if it has no text, the caption of message into segments.
+func Parse(message *entity.Message) []Segment {
+	if message == nil {
+		return nil
+	}
+	if message.Text == "" {
+		return ParseText(message.Caption, message.CaptionEntities)
+	}
+
+	return ParseText(message.Text, message.Entities)
+}
+
+// ParseText splits text into segments at the edges of entities.
+// Entities that don't fit in text are cut to it.
+func ParseText(text string, entities []entity.MessageEntity) []Segment {
+	encoded := utf16.Encode([]rune(text))
+	length := int64(len(encoded))
+
+	sorted := make([]entity.MessageEntity, 0, len(entities))
+	edges := []int64{0, length}
+	for _, e := range entities {
+		start, end := clamp(e.Offset, length), clamp(e.Offset+e.Length, length)
+		if start >= end {
+			continue
+		}
+		sorted = append(sorted, e)
+		edges = append(edges, start, end)
+	}
+
+	// outer entities start earlier or, at the same offset, are longer.
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Offset != sorted[j].Offset {
+			return sorted[i].Offset < sorted[j].Offset
+		}
+		return sorted[i].Length > sorted[j].Length
+	})
+	sort.Slice(edges, func(i, j int) bool { return edges[i] < edges[j] })
+
+	var segments []Segment
+	for i := 0; i+1 < len(edges); i++ {
+		start, end := edges[i], edges[i+1]
+		if start == end {
+			continue
+		}
+
+		segment := Segment{Text: string(utf16.Decode(encoded[start:end]))}
+		for _, e := range sorted {
+			if e.Offset <= start && end <= e.Offset+e.Length {
+				segment.Entities = append(segment.Entities, e)
+			}
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+func clamp(value, max int64) int64 {
+	if value < 0 {
+		return 0
+	}
+	if value > max {
+		return max
+	}
+
+	return value
+}
@@ -0,0 +1,77 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestParseText(t *testing.T) {
+	bold := entity.MessageEntity{Type: entity.EntityBold, Offset: 0, Length: 6}
+	italic := entity.MessageEntity{Type: entity.EntityItalic, Offset: 3, Length: 6}
+
+	tests := []struct {
+		name     string
+		text     string
+		entities []entity.MessageEntity
+		want     []Segment
+	}{
+		{
+			name: "plain",
+			text: "hello",
+			want: []Segment{{Text: "hello"}},
+		},
+		{
+			name:     "overlapping after emoji",
+			text:     "🎉 abcdef",
+			entities: []entity.MessageEntity{italic, bold},
+			want: []Segment{
+				{Text: "🎉 ", Entities: []entity.MessageEntity{bold}},
+				{Text: "abc", Entities: []entity.MessageEntity{bold, italic}},
+				{Text: "def", Entities: []entity.MessageEntity{italic}},
+			},
+		},
+		{
+			name:     "entities are cut to the text",
+			text:     "ab",
+			entities: []entity.MessageEntity{{Type: entity.EntityCode, Offset: 1, Length: 10}, {Type: entity.EntityBold, Offset: 5, Length: 1}},
+			want: []Segment{
+				{Text: "a"},
+				{Text: "b", Entities: []entity.MessageEntity{{Type: entity.EntityCode, Offset: 1, Length: 10}}},
+			},
+		},
+		{
+			name: "empty",
+			text: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseText(tt.text, tt.entities); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseText() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	link := entity.MessageEntity{Type: entity.EntityTextLink, Offset: 0, Length: 4, URL: "https://example.com"}
+	message := &entity.Message{Caption: "link", CaptionEntities: []entity.MessageEntity{link}}
+
+	segments := Parse(message)
+	if len(segments) != 1 || segments[0].Text != "link" {
+		t.Fatalf("Parse() = %+v", segments)
+	}
+	if e, ok := segments[0].Entity(entity.EntityTextLink); !ok || e.URL != link.URL {
+		t.Errorf("Entity() = %+v, %v", e, ok)
+	}
+	if segments[0].Has(entity.EntityBold) {
+		t.Error("Has(bold) = true")
+	}
+
+	if Parse(nil) != nil {
+		t.Error("Parse(nil) != nil")
+	}
+}
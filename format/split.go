@@ -0,0 +1,144 @@
+package format
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// Limits of the telegram server on the length of formatted text, in UTF-16 code units.
+const (
+	MaxTextLength    = 4096
+	MaxCaptionLength = 1024
+)
+
+// Chunk is a part of a text split with Split, along with its own entities.
+type Chunk struct {
+	Text     string
+	Entities []entity.MessageEntity
+}
+
+// Split splits text into chunks of at most limit UTF-16 code units.
+// It splits at the last paragraph break within the limit, then at the last line break,
+// then at the last space, and only cuts a word when none of them exists.
+// The breaks themselves and chunks of only white space are left out of the chunks.
+//
+// Entities crossing a split are cut into one entity in each chunk,
+// so every chunk keeps the formatting of its text.
+func Split(text string, entities []entity.MessageEntity, limit int64) []Chunk {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	encoded := utf16.Encode([]rune(text))
+	length := int64(len(encoded))
+	if limit <= 0 || length <= limit {
+		return []Chunk{{Text: text, Entities: entities}}
+	}
+
+	var chunks []Chunk
+	for start := int64(0); start < length; {
+		end, next := length, length
+		if length-start > limit {
+			end, next = splitPoint(encoded, start, start+limit)
+		}
+
+		if chunk := string(utf16.Decode(encoded[start:end])); strings.TrimSpace(chunk) != "" {
+			chunks = append(chunks, Chunk{
+				Text:     chunk,
+				Entities: clip(entities, start, end),
+			})
+		}
+		start = next
+	}
+
+	return chunks
+}
+
+// Cut cuts text at the same boundaries as Split into a head of at most limit UTF-16 code units
+// and the tail following it, which is empty if the whole text fits.
+func Cut(text string, entities []entity.MessageEntity, limit int64) (head, tail Chunk) {
+	encoded := utf16.Encode([]rune(text))
+	length := int64(len(encoded))
+	if limit <= 0 || length <= limit {
+		return Chunk{Text: text, Entities: entities}, Chunk{}
+	}
+
+	end, next := splitPoint(encoded, 0, limit)
+
+	return Chunk{Text: string(utf16.Decode(encoded[:end])), Entities: clip(entities, 0, end)},
+		Chunk{Text: string(utf16.Decode(encoded[next:])), Entities: clip(entities, next, length)}
+}
+
+// splitPoint returns where the chunk starting at start and ending at the latest at limit ends
+// and where the next chunk starts.
+func splitPoint(encoded []uint16, start, limit int64) (end, next int64) {
+	for _, separator := range [][]uint16{{'\n', '\n'}, {'\n'}, {' '}} {
+		// the separator may start right at the limit.
+		window := limit + int64(len(separator))
+		if window > int64(len(encoded)) {
+			window = int64(len(encoded))
+		}
+		if i := lastIndex(encoded[start:window], separator); i > 0 {
+			end, next = start+i, start+i+int64(len(separator))
+			// consecutive breaks neither end the chunk nor start the next one.
+			for end > start && encoded[end-1] == separator[0] {
+				end--
+			}
+			for next < int64(len(encoded)) && encoded[next] == separator[0] {
+				next++
+			}
+			if end > start {
+				return end, next
+			}
+		}
+	}
+
+	// a surrogate pair can't be cut.
+	if utf16.IsSurrogate(rune(encoded[limit])) && encoded[limit] >= 0xdc00 && limit-1 > start {
+		limit--
+	}
+
+	return limit, limit
+}
+
+// lastIndex returns the index of the last separator in s, or -1 if there is none.
+func lastIndex(s, separator []uint16) int64 {
+	for i := len(s) - len(separator); i >= 0; i-- {
+		found := true
+		for j := range separator {
+			if s[i+j] != separator[j] {
+				found = false
+				break
+			}
+		}
+		if found {
+			return int64(i)
+		}
+	}
+
+	return -1
+}
+
+// clip returns the parts of entities between start and end, with offsets from start.
+func clip(entities []entity.MessageEntity, start, end int64) []entity.MessageEntity {
+	var clipped []entity.MessageEntity
+	for _, e := range entities {
+		from, to := e.Offset, e.Offset+e.Length
+		if from < start {
+			from = start
+		}
+		if to > end {
+			to = end
+		}
+		if from >= to {
+			continue
+		}
+
+		e.Offset, e.Length = from-start, to-from
+		clipped = append(clipped, e)
+	}
+
+	return clipped
+}
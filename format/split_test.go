@@ -0,0 +1,142 @@
+package format
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities []entity.MessageEntity
+		limit    int64
+		want     []Chunk
+	}{
+		{
+			name:  "fits",
+			text:  "abc",
+			limit: 3,
+			want:  []Chunk{{Text: "abc"}},
+		},
+		{
+			name:  "blank",
+			text:  " \n ",
+			limit: 4096,
+		},
+		{
+			name:  "paragraph before line and space",
+			text:  "a b\n\nc d\ne",
+			limit: 8,
+			want:  []Chunk{{Text: "a b"}, {Text: "c d\ne"}},
+		},
+		{
+			name:  "line before space",
+			text:  "a b\nc d",
+			limit: 5,
+			want:  []Chunk{{Text: "a b"}, {Text: "c d"}},
+		},
+		{
+			name:  "break right at the limit",
+			text:  "abc def",
+			limit: 3,
+			want:  []Chunk{{Text: "abc"}, {Text: "def"}},
+		},
+		{
+			name:  "consecutive breaks",
+			text:  "ab\n\n\n\ncd",
+			limit: 3,
+			want:  []Chunk{{Text: "ab"}, {Text: "cd"}},
+		},
+		{
+			name:  "word is cut",
+			text:  "abcdefg",
+			limit: 3,
+			want:  []Chunk{{Text: "abc"}, {Text: "def"}, {Text: "g"}},
+		},
+		{
+			name:  "limit in UTF-16 code units",
+			text:  "🎉🎉 🎉",
+			limit: 4,
+			want:  []Chunk{{Text: "🎉🎉"}, {Text: "🎉"}},
+		},
+		{
+			name:  "surrogate pair at the limit",
+			text:  "ab🎉cd",
+			limit: 3,
+			want:  []Chunk{{Text: "ab"}, {Text: "🎉c"}, {Text: "d"}},
+		},
+		{
+			name:  "whitespace chunks are dropped",
+			text:  "ab\n\n   \n\ncd",
+			limit: 3,
+			want:  []Chunk{{Text: "ab"}, {Text: "cd"}},
+		},
+		{
+			name: "entities clipped across chunks",
+			text: "🎉 bold text\n\nnext",
+			entities: []entity.MessageEntity{
+				{Type: entity.EntityBold, Offset: 3, Length: 14},
+				{Type: entity.EntityTextLink, Offset: 14, Length: 4, URL: "https://example.com"},
+			},
+			limit: 12,
+			want: []Chunk{
+				{Text: "🎉 bold text", Entities: []entity.MessageEntity{{Type: entity.EntityBold, Offset: 3, Length: 9}}},
+				{Text: "next", Entities: []entity.MessageEntity{
+					{Type: entity.EntityBold, Offset: 0, Length: 3},
+					{Type: entity.EntityTextLink, Offset: 0, Length: 4, URL: "https://example.com"},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.text, tt.entities, tt.limit)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() = %+v, want %+v", got, tt.want)
+			}
+			for _, chunk := range got {
+				if Len(chunk.Text) > tt.limit {
+					t.Errorf("chunk %q is longer than %d", chunk.Text, tt.limit)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitLongText(t *testing.T) {
+	text := strings.Repeat("word 🎉 ", 2000)
+
+	chunks := Split(text, nil, MaxTextLength)
+	var joined []string
+	for _, chunk := range chunks {
+		if Len(chunk.Text) > MaxTextLength {
+			t.Fatalf("chunk of %d code units", Len(chunk.Text))
+		}
+		joined = append(joined, chunk.Text)
+	}
+
+	if got := strings.Join(joined, " "); got != text {
+		t.Error("chunks don't add up to the text")
+	}
+}
+
+func TestCut(t *testing.T) {
+	bold := []entity.MessageEntity{{Type: entity.EntityBold, Offset: 0, Length: 7}}
+
+	head, tail := Cut("abc def", bold, 5)
+	wantHead := Chunk{Text: "abc", Entities: []entity.MessageEntity{{Type: entity.EntityBold, Offset: 0, Length: 3}}}
+	wantTail := Chunk{Text: "def", Entities: []entity.MessageEntity{{Type: entity.EntityBold, Offset: 0, Length: 3}}}
+	if !reflect.DeepEqual(head, wantHead) || !reflect.DeepEqual(tail, wantTail) {
+		t.Errorf("Cut() = %+v, %+v, want %+v, %+v", head, tail, wantHead, wantTail)
+	}
+
+	head, tail = Cut("abc", bold, 5)
+	if head.Text != "abc" || tail.Text != "" || tail.Entities != nil {
+		t.Errorf("Cut() of a fitting text = %+v, %+v", head, tail)
+	}
+}
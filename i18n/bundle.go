@@ -0,0 +1,388 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// Message is a translated message.
+// A message without plural forms only has the Other form.
+type Message map[Plural]string
+
+// Args are the values of the placeholders of a message, like {name}.
+type Args map[string]any
+
+// Bundle holds the message catalogs of all languages.
+// It is safe for concurrent use.
+type Bundle struct {
+	fallback string
+
+	mu          sync.RWMutex
+	catalogs    map[string]map[string]Message
+	rules       map[string]PluralRule
+	preferences PreferenceStore
+}
+
+// NewBundle returns an empty Bundle.
+// fallback is the language used when a message is missing in the language of the user.
+func NewBundle(fallback string) *Bundle {
+	rules := make(map[string]PluralRule, len(defaultPluralRules))
+	for language, rule := range defaultPluralRules {
+		rules[language] = rule
+	}
+
+	return &Bundle{
+		fallback:    normalize(fallback),
+		catalogs:    map[string]map[string]Message{},
+		rules:       rules,
+		preferences: NewMemoryPreferences(),
+	}
+}
+
+// SetPluralRule sets the plural rule of language.
+func (b *Bundle) SetPluralRule(language string, rule PluralRule) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rules[normalize(language)] = rule
+}
+
+// SetPreferences replaces the store of the languages chosen by users.
+// By default the preferences are kept in memory.
+func (b *Bundle) SetPreferences(preferences PreferenceStore) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.preferences = preferences
+}
+
+// SetUserLanguage makes language the language of the user with the id userID,
+// regardless of the language of their telegram client.
+func (b *Bundle) SetUserLanguage(userID int64, language string) error {
+	b.mu.RLock()
+	preferences := b.preferences
+	b.mu.RUnlock()
+
+	return preferences.SetLanguage(userID, normalize(language))
+}
+
+// AddMessages adds messages to the catalog of language.
+// Messages already in the catalog are replaced.
+func (b *Bundle) AddMessages(language string, messages map[string]Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	language = normalize(language)
+	catalog, ok := b.catalogs[language]
+	if !ok {
+		catalog = map[string]Message{}
+		b.catalogs[language] = catalog
+	}
+
+	for key, message := range messages {
+		catalog[key] = message
+	}
+}
+
+// LoadFile loads the catalog in the file at path.
+// The format is chosen by the extension of the file, which can be .json, .yaml, .yml or .po,
+// and the language is the name of the file without the extension, like “fr.json”.
+func (b *Bundle) LoadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	extension := filepath.Ext(path)
+	language := strings.TrimSuffix(filepath.Base(path), extension)
+
+	switch strings.ToLower(extension) {
+	case ".json":
+		err = b.LoadJSON(language, content)
+	case ".yaml", ".yml":
+		err = b.LoadYAML(language, content)
+	case ".po":
+		err = b.LoadPO(language, content)
+	default:
+		return fmt.Errorf("unsupported catalog format %q", extension)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// LoadDir loads the catalogs of all the .json, .yaml, .yml and .po files in dir.
+// See LoadFile.
+func (b *Bundle) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".json", ".yaml", ".yml", ".po":
+			if err := b.LoadFile(filepath.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Localizer returns a Localizer for the first of languages that has a catalog.
+// It falls back to the fallback language of the bundle.
+func (b *Bundle) Localizer(languages ...string) *Localizer {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, language := range languages {
+		if supported, ok := b.supported(language); ok {
+			return &Localizer{bundle: b, language: supported}
+		}
+	}
+
+	return &Localizer{bundle: b, language: b.fallback}
+}
+
+// ForUpdate returns a Localizer for the user who sent update.
+// The language set with SetUserLanguage is preferred over the language of the client of the user.
+func (b *Bundle) ForUpdate(update entity.Update) *Localizer {
+	user := update.GetFrom()
+	if user == nil {
+		return b.Localizer()
+	}
+
+	b.mu.RLock()
+	preferences := b.preferences
+	b.mu.RUnlock()
+
+	// a failing store is not fatal, the language of the client is used instead.
+	if language, ok, err := preferences.Language(user.ID); err == nil && ok {
+		return b.Localizer(language, user.LanguageCode)
+	}
+
+	return b.Localizer(user.LanguageCode)
+}
+
+// Reply returns a message to the chat of update with the text of key
+// translated for the user who sent update.
+func (b *Bundle) Reply(update entity.Update, key string, args Args) entity.MessageEnvelop {
+	var chatID string
+	if chat := update.GetChat(); chat != nil {
+		chatID = strconv.FormatInt(chat.ID, 10)
+	}
+
+	return b.ForUpdate(update).Envelop(chatID, key, args)
+}
+
+// supported returns the language of a catalog matching language.
+// “en-US” matches a catalog of “en-us” or else “en”.
+// It must be called with the lock held.
+func (b *Bundle) supported(language string) (string, bool) {
+	language = normalize(language)
+	if language == "" {
+		return "", false
+	}
+	if _, ok := b.catalogs[language]; ok {
+		return language, true
+	}
+
+	if index := strings.Index(language, "-"); index != -1 {
+		if _, ok := b.catalogs[language[:index]]; ok {
+			return language[:index], true
+		}
+	}
+
+	return "", false
+}
+
+// lookup returns the text of the message key in language or else in the fallback language.
+// The form of the message is chosen by form with the plural rule of the language the message is in,
+// or is Other if form is nil. A message without that form falls back to its Other form,
+// and a message whose text is empty is treated as missing.
+func (b *Bundle) lookup(language, key string, form func(rule PluralRule) Plural) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, l := range []string{language, b.fallback} {
+		message, ok := b.catalogs[l][key]
+		if !ok {
+			continue
+		}
+
+		text := ""
+		if form != nil {
+			text = message[form(b.ruleOf(l))]
+		}
+		if text == "" {
+			text = message[Other]
+		}
+		if text != "" {
+			return text, true
+		}
+	}
+
+	return "", false
+}
+
+// rule returns the plural rule of language.
+func (b *Bundle) rule(language string) PluralRule {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.ruleOf(language)
+}
+
+// ruleOf returns the plural rule of language.
+// It must be called with the lock held.
+func (b *Bundle) ruleOf(language string) PluralRule {
+	if rule, ok := b.rules[language]; ok {
+		return rule
+	}
+	if index := strings.Index(language, "-"); index != -1 {
+		if rule, ok := b.rules[language[:index]]; ok {
+			return rule
+		}
+	}
+
+	return defaultPluralRules["en"]
+}
+
+// normalize returns language in lower case with '-' as the separator of its subtags.
+func normalize(language string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+}
+
+// Localizer translates messages into a single language.
+type Localizer struct {
+	bundle   *Bundle
+	language string
+}
+
+// Language returns the language of the localizer.
+func (l *Localizer) Language() string {
+	return l.language
+}
+
+// T returns the message key with its placeholders replaced by args.
+// It returns key itself if the message is empty or missing in both the language of the localizer
+// and the fallback language.
+func (l *Localizer) T(key string, args Args) string {
+	text, ok := l.bundle.lookup(l.language, key, nil)
+	if !ok {
+		return key
+	}
+
+	return interpolate(text, args)
+}
+
+// N returns the plural form of the message key for count with its placeholders replaced by args.
+// The {count} placeholder is set to count.
+// Like T, it returns key itself if the message is empty or missing.
+func (l *Localizer) N(key string, count int64, args Args) string {
+	text, ok := l.bundle.lookup(l.language, key, func(rule PluralRule) Plural {
+		return rule.Select(count)
+	})
+	if !ok {
+		return key
+	}
+
+	withCount := make(Args, len(args)+1)
+	for name, value := range args {
+		withCount[name] = value
+	}
+	withCount["count"] = count
+
+	return interpolate(text, withCount)
+}
+
+// Envelop returns a message to chatID with the text of key.
+func (l *Localizer) Envelop(chatID string, key string, args Args) entity.MessageEnvelop {
+	return entity.MessageEnvelop{
+		ChatID: chatID,
+		Text:   l.T(key, args),
+	}
+}
+
+// interpolate replaces the {name} placeholders of text with the values of args.
+// Placeholders without a value are kept as they are.
+func interpolate(text string, args Args) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	var result strings.Builder
+	for {
+		start := strings.Index(text, "{")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(text[start:], "}")
+		if end == -1 {
+			break
+		}
+		end += start
+
+		result.WriteString(text[:start])
+		if value, ok := args[text[start+1:end]]; ok {
+			result.WriteString(fmt.Sprint(value))
+		} else {
+			result.WriteString(text[start : end+1])
+		}
+		text = text[end+1:]
+	}
+	result.WriteString(text)
+
+	return result.String()
+}
+
+// PreferenceStore stores the languages users chose for the bot.
+// Implementations must be safe for concurrent use.
+type PreferenceStore interface {
+	// Language returns the language chosen by the user with the id userID.
+	// The second return value is false if the user didn't choose one.
+	Language(userID int64) (string, bool, error)
+	// SetLanguage stores the language chosen by the user with the id userID.
+	SetLanguage(userID int64, language string) error
+}
+
+// MemoryPreferences is a PreferenceStore that keeps the preferences in memory.
+type MemoryPreferences struct {
+	mu        sync.RWMutex
+	languages map[int64]string
+}
+
+// NewMemoryPreferences returns a new empty MemoryPreferences.
+func NewMemoryPreferences() *MemoryPreferences {
+	return &MemoryPreferences{
+		languages: map[int64]string{},
+	}
+}
+
+func (p *MemoryPreferences) Language(userID int64) (string, bool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	language, ok := p.languages[userID]
+
+	return language, ok, nil
+}
+
+func (p *MemoryPreferences) SetLanguage(userID int64, language string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.languages[userID] = language
+
+	return nil
+}
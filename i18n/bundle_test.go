@@ -0,0 +1,187 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestLocalizer(t *testing.T) {
+	b := NewBundle("en")
+	if err := b.LoadJSON("en", []byte(`{
+		"welcome": "Hello {name}",
+		"bye": "Bye",
+		"apples": {"one": "{count} apple", "other": "{count} apples"},
+		"pears": {"one": "{count} pear"},
+		"menu": {"start": "Start"}
+	}`)); err != nil {
+		t.Fatalf("LoadJSON() error = %v", err)
+	}
+	if err := b.LoadYAML("fr", []byte("welcome: Bonjour {name}\nbye: \"\"\napples:\n  one: \"{count} pomme\"\n  other: \"{count} pommes\"\n")); err != nil {
+		t.Fatalf("LoadYAML() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		language string
+		got      func(l *Localizer) string
+		want     string
+	}{
+		{
+			name:     "translated",
+			language: "fr",
+			got:      func(l *Localizer) string { return l.T("welcome", Args{"name": "Ana"}) },
+			want:     "Bonjour Ana",
+		},
+		{
+			name:     "missing placeholder is kept",
+			language: "fr",
+			got:      func(l *Localizer) string { return l.T("welcome", Args{"other": 1}) },
+			want:     "Bonjour {name}",
+		},
+		{
+			name:     "empty text falls back",
+			language: "fr",
+			got:      func(l *Localizer) string { return l.T("bye", nil) },
+			want:     "Bye",
+		},
+		{
+			name:     "missing in language falls back",
+			language: "fr",
+			got:      func(l *Localizer) string { return l.T("menu.start", nil) },
+			want:     "Start",
+		},
+		{
+			name:     "missing key",
+			language: "fr",
+			got:      func(l *Localizer) string { return l.T("missing", nil) },
+			want:     "missing",
+		},
+		{
+			name:     "plural without other is missing for T",
+			language: "en",
+			got:      func(l *Localizer) string { return l.T("pears", nil) },
+			want:     "pears",
+		},
+		{
+			name:     "zero is singular in french",
+			language: "fr",
+			got:      func(l *Localizer) string { return l.N("apples", 0, nil) },
+			want:     "0 pomme",
+		},
+		{
+			name:     "zero is plural in english",
+			language: "en",
+			got:      func(l *Localizer) string { return l.N("apples", 0, nil) },
+			want:     "0 apples",
+		},
+		{
+			name:     "missing form falls back to other",
+			language: "en",
+			got:      func(l *Localizer) string { return l.N("pears", 2, nil) },
+			want:     "pears",
+		},
+		{
+			name:     "region falls back to language",
+			language: "fr-CA",
+			got:      func(l *Localizer) string { return l.N("apples", 1, nil) },
+			want:     "1 pomme",
+		},
+		{
+			name:     "unsupported language",
+			language: "de",
+			got:      func(l *Localizer) string { return l.N("apples", 1, nil) },
+			want:     "1 apple",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got(b.Localizer(tt.language)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		language string
+		counts   map[int64]Plural
+	}{
+		{language: "en", counts: map[int64]Plural{0: Other, 1: One, 2: Other, 21: Other}},
+		{language: "fr", counts: map[int64]Plural{0: One, 1: One, 2: Other}},
+		{language: "am", counts: map[int64]Plural{0: One, 1: One, 5: Other}},
+		{language: "de", counts: map[int64]Plural{0: Other, 1: One}},
+	}
+
+	b := NewBundle("en")
+	for _, tt := range tests {
+		rule := b.rule(tt.language)
+		for count, want := range tt.counts {
+			if got := rule.Select(count); got != want {
+				t.Errorf("%s: Select(%d) = %s, want %s", tt.language, count, got, want)
+			}
+		}
+	}
+}
+
+func TestForUpdate(t *testing.T) {
+	b := NewBundle("en")
+	b.AddMessages("en", map[string]Message{"hi": {Other: "Hi"}})
+	b.AddMessages("fr", map[string]Message{"hi": {Other: "Salut"}})
+	b.AddMessages("am", map[string]Message{"hi": {Other: "ሰላም"}})
+
+	update := entity.Update{Message: &entity.Message{
+		From: &entity.User{ID: 1, LanguageCode: "fr"},
+		Chat: &entity.Chat{ID: 10},
+	}}
+
+	if got := b.Reply(update, "hi", nil); got.Text != "Salut" || got.ChatID != "10" {
+		t.Errorf("Reply() = %+v", got)
+	}
+
+	if err := b.SetUserLanguage(1, "AM"); err != nil {
+		t.Fatalf("SetUserLanguage() error = %v", err)
+	}
+	if got := b.ForUpdate(update).Language(); got != "am" {
+		t.Errorf("ForUpdate().Language() = %q, want am", got)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"en.json":   `{"hi": "Hi"}`,
+		"fr.yml":    "hi: Salut\n",
+		"am.po":     "msgid \"hi\"\nmsgstr \"ሰላም\"\n",
+		"notes.txt": "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := NewBundle("en")
+	if err := b.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	for language, want := range map[string]string{"en": "Hi", "fr": "Salut", "am": "ሰላም"} {
+		if got := b.Localizer(language).T("hi", nil); got != want {
+			t.Errorf("%s: T() = %q, want %q", language, got, want)
+		}
+	}
+
+	if err := b.LoadFile(filepath.Join(dir, "notes.txt")); err == nil {
+		t.Error("LoadFile() of an unsupported format succeeded")
+	}
+}
+
+func TestLoadJSONInvalidMessage(t *testing.T) {
+	if err := NewBundle("en").LoadJSON("en", []byte(`{"a": 1}`)); err == nil {
+		t.Error("LoadJSON() of a number succeeded")
+	}
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LoadJSON loads a catalog of language from JSON.
+//
+// Messages are strings, plural messages are objects of plural categories
+// and other objects group messages under dotted keys:
+//
+//	{
+//		"welcome": "Hello {name}",
+//		"apples": {"one": "{count} apple", "other": "{count} apples"},
+//		"menu": {"start": "Start"}
+//	}
+//
+// The keys of this catalog are “welcome”, “apples” and “menu.start”.
+func (b *Bundle) LoadJSON(language string, data []byte) error {
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+
+	messages := map[string]Message{}
+	if err := flatten(messages, "", tree); err != nil {
+		return err
+	}
+	b.AddMessages(language, messages)
+
+	return nil
+}
+
+// LoadYAML loads a catalog of language from YAML.
+// The catalog is structured like the one of LoadJSON.
+//
+// Only the part of YAML needed for catalogs is supported: nested mappings with plain, quoted
+// and block (| and >) string values, and comments.
+// Flow collections like {a: b} and sequences are not supported and return an error.
+func (b *Bundle) LoadYAML(language string, data []byte) error {
+	tree, err := parseYAML(data)
+	if err != nil {
+		return err
+	}
+
+	messages := map[string]Message{}
+	if err := flatten(messages, "", tree); err != nil {
+		return err
+	}
+	b.AddMessages(language, messages)
+
+	return nil
+}
+
+// flatten adds the messages of tree to messages with their keys prefixed by prefix.
+func flatten(messages map[string]Message, prefix string, tree map[string]any) error {
+	for key, value := range tree {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch value := value.(type) {
+		case string:
+			messages[key] = Message{Other: value}
+		case map[string]any:
+			if message, ok := pluralMessage(value); ok {
+				messages[key] = message
+				continue
+			}
+			if err := flatten(messages, key, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %q must be a string or an object, got %T", key, value)
+		}
+	}
+
+	return nil
+}
+
+// pluralMessage returns value as a plural message if all its keys are plural categories with string values.
+func pluralMessage(value map[string]any) (Message, bool) {
+	if len(value) == 0 {
+		return nil, false
+	}
+
+	message := Message{}
+	for key, form := range value {
+		text, ok := form.(string)
+		if !ok || !isPlural(key) {
+			return nil, false
+		}
+		message[Plural(key)] = text
+	}
+
+	return message, true
+}
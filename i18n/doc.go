@@ -0,0 +1,4 @@
+// Package i18n translates the replies of a bot.
+// Message catalogs are loaded from JSON, YAML or PO files and looked up in the language of the user
+// who sent an update, with plural forms and {placeholder} interpolation.
+package i18n
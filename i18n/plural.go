@@ -0,0 +1,63 @@
+package i18n
+
+// Plural is a CLDR plural category.
+type Plural string
+
+// Plural categories.
+const (
+	Zero  Plural = "zero"
+	One   Plural = "one"
+	Two   Plural = "two"
+	Few   Plural = "few"
+	Many  Plural = "many"
+	Other Plural = "other"
+)
+
+// PluralRule decides the plural form of a count in a language.
+type PluralRule struct {
+	// Forms are the plural categories of the language
+	// in the order of the msgstr[n] translations of PO files.
+	Forms []Plural
+	// Select returns the category of count.
+	Select func(count int64) Plural
+}
+
+// defaultPluralRules are the plural rules of the languages supported out of the box.
+// Other languages use the English rule unless a rule is set with Bundle.SetPluralRule.
+var defaultPluralRules = map[string]PluralRule{
+	"en": {
+		Forms: []Plural{One, Other},
+		Select: func(count int64) Plural {
+			if count == 1 {
+				return One
+			}
+			return Other
+		},
+	},
+	// zero and one are singular in French and Amharic.
+	"fr": {
+		Forms:  []Plural{One, Other},
+		Select: zeroOrOne,
+	},
+	"am": {
+		Forms:  []Plural{One, Other},
+		Select: zeroOrOne,
+	},
+}
+
+func zeroOrOne(count int64) Plural {
+	if count == 0 || count == 1 {
+		return One
+	}
+
+	return Other
+}
+
+func isPlural(key string) bool {
+	switch Plural(key) {
+	case Zero, One, Two, Few, Many, Other:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,207 @@
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// poEntry is a translation of a PO file.
+type poEntry struct {
+	context string
+	id      string
+	plural  string
+	strs    map[int]string
+	fuzzy   bool
+	// lastWord and lastIdx tell which field continuation strings are appended to.
+	lastWord string
+	lastIdx  int
+}
+
+// LoadPO loads a catalog of language from a gettext PO file.
+//
+// The key of a message is its msgid, prefixed by its msgctxt and a '.' if it has one.
+// The msgstr[n] translations of plural messages are mapped to the plural categories
+// of the plural rule of language, which is set with Bundle.SetPluralRule.
+// The plural expression of the Plural-Forms header is not evaluated,
+// but its nplurals must match the number of forms of the rule.
+// Fuzzy, obsolete and untranslated entries are skipped.
+func (b *Bundle) LoadPO(language string, data []byte) error {
+	entries, err := parsePO(data)
+	if err != nil {
+		return err
+	}
+
+	forms := b.rule(normalize(language)).Forms
+
+	messages := map[string]Message{}
+	for _, e := range entries {
+		if e.id == "" && e.context == "" {
+			// the header of the file.
+			if n, ok := nplurals(e.strs[0]); ok && n != len(forms) {
+				return fmt.Errorf("po: Plural-Forms has %d forms but the plural rule of %q has %d", n, language, len(forms))
+			}
+			continue
+		}
+		if e.fuzzy {
+			continue
+		}
+
+		key := e.id
+		if e.context != "" {
+			key = e.context + "." + e.id
+		}
+
+		message := Message{}
+		if e.plural == "" {
+			if e.strs[0] != "" {
+				message[Other] = e.strs[0]
+			}
+		} else {
+			for index, text := range e.strs {
+				if index < len(forms) && text != "" {
+					message[forms[index]] = text
+				}
+			}
+		}
+
+		if len(message) != 0 {
+			messages[key] = message
+		}
+	}
+	b.AddMessages(language, messages)
+
+	return nil
+}
+
+// parsePO parses the entries of a PO file.
+func parsePO(data []byte) ([]*poEntry, error) {
+	var entries []*poEntry
+	current := &poEntry{strs: map[int]string{}}
+	started := false
+
+	flush := func() {
+		if started {
+			entries = append(entries, current)
+		}
+		current = &poEntry{strs: map[int]string{}}
+		started = false
+	}
+
+	for number, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#,"):
+			if started && current.lastWord == "msgstr" {
+				flush()
+			}
+			current.fuzzy = current.fuzzy || hasFlag(line, "fuzzy")
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, `"`):
+			if !started {
+				return nil, fmt.Errorf("po: line %d: string without a keyword", number+1)
+			}
+			text, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("po: line %d: %w", number+1, err)
+			}
+			current.append(text)
+		default:
+			word, rest, _ := strings.Cut(line, " ")
+			text, err := strconv.Unquote(strings.TrimSpace(rest))
+			if err != nil {
+				return nil, fmt.Errorf("po: line %d: %w", number+1, err)
+			}
+
+			// a keyword starting a new message ends the previous one even without an empty line between them.
+			if started && current.lastWord == "msgstr" && (word == "msgid" || word == "msgctxt") {
+				flush()
+			}
+			started = true
+
+			if err := current.set(word, text); err != nil {
+				return nil, fmt.Errorf("po: line %d: %w", number+1, err)
+			}
+		}
+	}
+	flush()
+
+	return entries, nil
+}
+
+// hasFlag reports whether the “#,” flags comment line has flag.
+func hasFlag(line, flag string) bool {
+	for _, f := range strings.Split(strings.TrimPrefix(line, "#,"), ",") {
+		if strings.TrimSpace(f) == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nplurals returns the number of plural forms in the Plural-Forms field of a PO header.
+func nplurals(header string) (int, bool) {
+	for _, field := range strings.Split(header, "\n") {
+		name, value, ok := strings.Cut(field, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "Plural-Forms") {
+			continue
+		}
+
+		for _, part := range strings.Split(value, ";") {
+			name, value, ok := strings.Cut(part, "=")
+			if ok && strings.TrimSpace(name) == "nplurals" {
+				n, err := strconv.Atoi(strings.TrimSpace(value))
+				return n, err == nil
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// set sets the field of the entry named by the keyword word.
+func (e *poEntry) set(word, text string) error {
+	switch {
+	case word == "msgctxt":
+		e.context = text
+	case word == "msgid":
+		e.id = text
+	case word == "msgid_plural":
+		e.plural = text
+	case word == "msgstr":
+		e.strs[0] = text
+		e.lastIdx = 0
+	case strings.HasPrefix(word, "msgstr[") && strings.HasSuffix(word, "]"):
+		index, err := strconv.Atoi(word[len("msgstr[") : len(word)-1])
+		if err != nil {
+			return fmt.Errorf("invalid plural index %q", word)
+		}
+		e.strs[index] = text
+		e.lastIdx = index
+		word = "msgstr"
+	default:
+		return fmt.Errorf("unknown keyword %q", word)
+	}
+	e.lastWord = word
+
+	return nil
+}
+
+// append appends a continuation string to the field set last.
+func (e *poEntry) append(text string) {
+	switch e.lastWord {
+	case "msgctxt":
+		e.context += text
+	case "msgid":
+		e.id += text
+	case "msgid_plural":
+		e.plural += text
+	case "msgstr":
+		e.strs[e.lastIdx] += text
+	}
+}
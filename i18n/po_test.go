@@ -0,0 +1,131 @@
+package i18n
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPO = `msgid ""
+msgstr ""
+"Language: fr\n"
+"Plural-Forms: nplurals=2; plural=(n > 1);\n"
+
+# a translator comment
+#: main.go:10
+msgid "welcome"
+msgstr "Bonjour {name}"
+
+#, c-format
+msgid "multi"
+msgstr ""
+"une "
+"ligne"
+
+msgctxt "menu"
+msgid "start"
+msgstr "Démarrer"
+
+#, fuzzy
+#, c-format
+msgid "fuzzy"
+msgstr "flou"
+
+#, c-format, fuzzy
+msgid "fuzzy2"
+msgstr "flou"
+
+msgid "untranslated"
+msgstr ""
+
+#~ msgid "obsolete"
+#~ msgstr "obsolète"
+
+msgid "apple"
+msgid_plural "apples"
+msgstr[0] "{count} pomme"
+msgstr[1] "{count} pommes"
+msgid "next"
+msgstr "suivant"
+`
+
+func TestLoadPO(t *testing.T) {
+	b := NewBundle("fr")
+	if err := b.LoadPO("fr", []byte(testPO)); err != nil {
+		t.Fatalf("LoadPO() error = %v", err)
+	}
+
+	want := map[string]Message{
+		"welcome":    {Other: "Bonjour {name}"},
+		"multi":      {Other: "une ligne"},
+		"menu.start": {Other: "Démarrer"},
+		"apple":      {One: "{count} pomme", Other: "{count} pommes"},
+		"next":       {Other: "suivant"},
+	}
+	if got := b.catalogs["fr"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("catalog = %#v, want %#v", got, want)
+	}
+}
+
+func TestLoadPOErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "plural forms mismatch",
+			data:    "msgid \"\"\nmsgstr \"Plural-Forms: nplurals=3; plural=(n%10==1 ? 0 : 1);\\n\"\n",
+			wantErr: `Plural-Forms has 3 forms but the plural rule of "fr" has 2`,
+		},
+		{
+			name:    "string without keyword",
+			data:    "\"text\"\n",
+			wantErr: "line 1: string without a keyword",
+		},
+		{
+			name:    "unknown keyword",
+			data:    "msgid \"a\"\nmsgtxt \"b\"\n",
+			wantErr: `line 2: unknown keyword "msgtxt"`,
+		},
+		{
+			name:    "bad quoting",
+			data:    "msgid a\n",
+			wantErr: "line 1:",
+		},
+		{
+			name:    "bad plural index",
+			data:    "msgid \"a\"\nmsgstr[x] \"b\"\n",
+			wantErr: `invalid plural index "msgstr[x]"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewBundle("fr").LoadPO("fr", []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadPO() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNPlurals(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+		wantOK bool
+	}{
+		{header: "Language: en\nPlural-Forms: nplurals=2; plural=(n != 1);\n", want: 2, wantOK: true},
+		{header: "plural-forms:plural=0; nplurals = 1\n", want: 1, wantOK: true},
+		{header: "Language: en\n"},
+		{header: "Plural-Forms: nplurals=x;\n"},
+	}
+
+	for _, tt := range tests {
+		got, ok := nplurals(tt.header)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("nplurals(%q) = %d, %v, want %d, %v", tt.header, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a significant line of a YAML document.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML parses a YAML document made of nested mappings with string values.
+func parseYAML(data []byte) (map[string]any, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		lines = append(lines, yamlLine{
+			number: i + 1,
+			indent: len(raw) - len(strings.TrimLeft(raw, " ")),
+			text:   strings.TrimLeft(raw, " "),
+		})
+	}
+
+	p := &yamlParser{lines: lines}
+	tree, err := p.mapping(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.skip(); p.index < len(p.lines) {
+		return nil, p.errorf("unexpected indentation")
+	}
+
+	return tree, nil
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	index int
+}
+
+// errorf returns an error at the current line.
+func (p *yamlParser) errorf(format string, args ...any) error {
+	var line yamlLine
+	if p.index < len(p.lines) {
+		line = p.lines[p.index]
+	}
+
+	return line.errorf(format, args...)
+}
+
+func (l yamlLine) errorf(format string, args ...any) error {
+	return fmt.Errorf("yaml: line %d: %s", l.number, fmt.Sprintf(format, args...))
+}
+
+// skip moves past blank lines, comments and document markers.
+func (p *yamlParser) skip() {
+	for ; p.index < len(p.lines); p.index++ {
+		text := p.lines[p.index].text
+		if text != "" && !strings.HasPrefix(text, "#") && text != "---" {
+			return
+		}
+	}
+}
+
+// mapping parses the mapping whose keys are indented by indent.
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	tree := map[string]any{}
+
+	for p.skip(); p.index < len(p.lines); p.skip() {
+		line := p.lines[p.index]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if strings.HasPrefix(line.text, "\t") {
+			return nil, p.errorf("tabs can't be used for indentation")
+		}
+		if line.text == "-" || strings.HasPrefix(line.text, "- ") {
+			return nil, p.errorf("sequences are not supported")
+		}
+
+		key, rest, err := splitKey(line.text)
+		if err != nil {
+			return nil, p.errorf("%s", err)
+		}
+		p.index++
+
+		rest = stripComment(rest)
+		switch {
+		case rest == "":
+			p.skip()
+			if p.index < len(p.lines) && p.lines[p.index].indent > indent {
+				tree[key], err = p.mapping(p.lines[p.index].indent)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				tree[key] = ""
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			tree[key] = p.block(indent, rest)
+		case rest[0] == '{' || rest[0] == '[':
+			return nil, line.errorf("flow collections are not supported")
+		default:
+			tree[key], err = scalar(rest)
+			if err != nil {
+				return nil, line.errorf("%s", err)
+			}
+		}
+	}
+
+	return tree, nil
+}
+
+// block parses a literal (|) or folded (>) block scalar indented by more than indent.
+func (p *yamlParser) block(indent int, header string) string {
+	folded, strip := header[0] == '>', strings.Contains(header, "-")
+
+	var lines []string
+	blockIndent := -1
+	for ; p.index < len(p.lines); p.index++ {
+		line := p.lines[p.index]
+		if line.text == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if line.indent <= indent {
+			break
+		}
+		if blockIndent == -1 {
+			blockIndent = line.indent
+		}
+		lines = append(lines, strings.Repeat(" ", line.indent-blockIndent)+line.text)
+	}
+
+	for len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var text string
+	if folded {
+		text = foldLines(lines)
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+	if !strip && text != "" {
+		text += "\n"
+	}
+
+	return text
+}
+
+// foldLines joins lines with spaces, except that empty lines become line breaks.
+func foldLines(lines []string) string {
+	var text strings.Builder
+	for i, line := range lines {
+		switch {
+		case line == "":
+			text.WriteString("\n")
+		case i > 0 && lines[i-1] != "":
+			text.WriteString(" " + line)
+		default:
+			text.WriteString(line)
+		}
+	}
+
+	return text.String()
+}
+
+// splitKey splits a “key: value” line into its key and the rest of the line.
+func splitKey(text string) (string, string, error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end == -1 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		key, err := scalar(text[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+		rest := strings.TrimLeft(text[end+1:], " ")
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", fmt.Errorf("missing ':' after key")
+		}
+
+		return key, strings.TrimSpace(rest[1:]), nil
+	}
+
+	index := strings.Index(text, ": ")
+	if index == -1 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", fmt.Errorf("expected a 'key: value' pair")
+		}
+		index = len(text) - 1
+	}
+
+	return strings.TrimSpace(text[:index]), strings.TrimSpace(text[index+1:]), nil
+}
+
+// closingQuote returns the index of the quote closing the quoted string text starts with.
+func closingQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote:
+			if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+
+	return -1
+}
+
+// stripComment removes a trailing comment from a value.
+func stripComment(value string) string {
+	if value == "" {
+		return value
+	}
+	if value[0] == '"' || value[0] == '\'' {
+		if end := closingQuote(value); end != -1 {
+			return value[:end+1]
+		}
+		return value
+	}
+	if strings.HasPrefix(value, "#") {
+		return ""
+	}
+	if index := strings.Index(value, " #"); index != -1 {
+		return strings.TrimSpace(value[:index])
+	}
+
+	return value
+}
+
+// scalar returns the string value of a plain or quoted scalar.
+func scalar(value string) (string, error) {
+	switch value[0] {
+	case '"':
+		return strconv.Unquote(value)
+	case '\'':
+		if len(value) < 2 || value[len(value)-1] != '\'' {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+
+	return value, nil
+}
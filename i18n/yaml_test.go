@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "nested mappings and comments",
+			data: "# a catalog\n---\nwelcome: Hello {name} # greeting\nmenu:\n  start: Start\n\n  # help\n  help: 'Help #1'\n",
+			want: map[string]any{
+				"welcome": "Hello {name}",
+				"menu":    map[string]any{"start": "Start", "help": "Help #1"},
+			},
+		},
+		{
+			name: "quoted keys and values",
+			data: "\"a: b\": \"line\\nbreak\"\n'it''s': 'it''s'\nempty:\n",
+			want: map[string]any{"a: b": "line\nbreak", "it's": "it's", "empty": ""},
+		},
+		{
+			name: "literal block",
+			data: "text: |\n  first\n    indented\n\n  last\nnext: x\n",
+			want: map[string]any{"text": "first\n  indented\n\nlast\n", "next": "x"},
+		},
+		{
+			name: "folded block with strip",
+			data: "text: >-\n  a\n  b\n\n  c\n",
+			want: map[string]any{"text": "a b\nc"},
+		},
+		{
+			name:    "bad indentation",
+			data:    "a: x\n   b: y\n",
+			wantErr: "line 2: unexpected indentation",
+		},
+		{
+			name:    "nested bad indentation",
+			data:    "a:\n    b: x\n  c: y\n",
+			wantErr: "line 3: unexpected indentation",
+		},
+		{
+			name:    "flow mapping",
+			data:    "a: {b: c}\n",
+			wantErr: "line 1: flow collections are not supported",
+		},
+		{
+			name:    "flow sequence",
+			data:    "a: x\nb: [c, d]\n",
+			wantErr: "line 2: flow collections are not supported",
+		},
+		{
+			name:    "sequence",
+			data:    "a:\n  - b\n",
+			wantErr: "line 2: sequences are not supported",
+		},
+		{
+			name:    "missing colon",
+			data:    "a\n",
+			wantErr: "line 1: expected a 'key: value' pair",
+		},
+		{
+			name:    "unterminated quoted key",
+			data:    "\"a: b\n",
+			wantErr: "line 1: unterminated quoted key",
+		},
+		{
+			name:    "tab",
+			data:    "a:\n\tb: c\n",
+			wantErr: "tabs can't be used for indentation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseYAML() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseYAML() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package gotbot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Call describes a single request to the telegram server as seen by interceptors.
+type Call struct {
+	// HTTPMethod is the http method of the request.
+	HTTPMethod string
+	// Function is the name of the telegram function that is called. eg: sendMessage
+	Function string
+	// Options hold information about the body of the request.
+	Options BodyOptions
+	// Header is added to the headers of the http request.
+	// Interceptors can use it to attach tracing headers and the like.
+	Header http.Header
+	// Attempt is the number of this attempt, starting from 1.
+	// It is only greater than 1 if the request is retried by the RetryPolicy.
+	Attempt int
+}
+
+// Invoker sends a call to the telegram server and returns the result part of the response
+// or the error it failed with.
+type Invoker func(ctx context.Context, call *Call) ([]byte, error)
+
+// Interceptor wraps an Invoker.
+//
+// An interceptor can inspect and modify call before passing it to next,
+// and inspect or replace the result and the error next returns.
+// It can also short-circuit the call by returning without calling next at all.
+//
+// The result is the raw json of the result part of the response. It is only decoded into the response
+// of the Bot method once all interceptors returned, so an interceptor that replaces the result
+// must return json of the type the method expects.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) ([]byte, error)
+
+// chainInterceptors wraps invoker with interceptors so that the first interceptor is the outermost.
+func chainInterceptors(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, call *Call) ([]byte, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	return invoker
+}
+
+// LoggingInterceptor returns an interceptor that logs every call with its duration and outcome.
+func LoggingInterceptor(logger Logger) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+		start := time.Now()
+		result, err := next(ctx, call)
+
+		fields := Fields{
+			"function": call.Function,
+			"attempt":  call.Attempt,
+			"duration": time.Since(start).String(),
+		}
+		if call.Options.ChatID != "" {
+			fields["chat_id"] = call.Options.ChatID
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+			logger.Warn("telegram call failed", fields)
+		} else {
+			logger.Debug("telegram call succeeded", fields)
+		}
+
+		return result, err
+	}
+}
+
+// RedactTokenInterceptor returns an interceptor that removes token from the messages of returned errors.
+// Errors of the http client contain the request url, which includes the bot token.
+func RedactTokenInterceptor(token string) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+		result, err := next(ctx, call)
+		if err != nil && token != "" && strings.Contains(err.Error(), token) {
+			err = &redactedError{
+				err: err,
+				msg: strings.ReplaceAll(err.Error(), token, "<redacted>"),
+			}
+		}
+
+		return result, err
+	}
+}
+
+// redactedError replaces the message of err while keeping it available to errors.Is and errors.As.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// FaultInterceptor returns an interceptor that fails calls to the given telegram functions with err
+// without sending them. All calls fail if no function is given.
+// It is meant to be used in tests.
+func FaultInterceptor(err error, functions ...string) Interceptor {
+	if err == nil {
+		err = errors.New("injected fault")
+	}
+
+	return func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+		if len(functions) == 0 {
+			return nil, err
+		}
+		for _, function := range functions {
+			if function == call.Function {
+				return nil, err
+			}
+		}
+
+		return next(ctx, call)
+	}
+}
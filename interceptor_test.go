@@ -0,0 +1,151 @@
+package gotbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestChainInterceptors(t *testing.T) {
+	var order []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+			order = append(order, name+" before")
+			call.Header.Add("X-Order", name)
+			result, err := next(ctx, call)
+			order = append(order, name+" after")
+
+			return result, err
+		}
+	}
+
+	invoker := chainInterceptors([]Interceptor{record("first"), record("second")}, func(ctx context.Context, call *Call) ([]byte, error) {
+		order = append(order, "invoker")
+		return []byte(strings.Join(call.Header.Values("X-Order"), ",")), nil
+	})
+
+	result, err := invoker(context.Background(), &Call{Header: http.Header{}})
+	if err != nil || string(result) != "first,second" {
+		t.Errorf("invoker() = %q, %v", result, err)
+	}
+
+	want := []string{"first before", "second before", "invoker", "second after", "first after"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestInterceptorsAroundRequests(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = fmt.Fprintf(w, `{"ok":true,"result":{"id":1,"is_bot":true,"username":"%s"}}`, r.Header.Get("X-Trace"))
+	}))
+	defer server.Close()
+
+	injected := errors.New("injected")
+	tests := []struct {
+		name         string
+		interceptors []Interceptor
+		wantUsername string
+		wantErr      error
+		wantRequests int32
+	}{
+		{
+			name: "header reaches the server",
+			interceptors: []Interceptor{func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+				call.Header.Set("X-Trace", "traced")
+				return next(ctx, call)
+			}},
+			wantUsername: "traced",
+			wantRequests: 1,
+		},
+		{
+			name: "short circuit",
+			interceptors: []Interceptor{func(ctx context.Context, call *Call, next Invoker) ([]byte, error) {
+				return []byte(`{"id":2,"is_bot":true,"username":"cached"}`), nil
+			}},
+			wantUsername: "cached",
+		},
+		{
+			name:         "fault for the function",
+			interceptors: []Interceptor{FaultInterceptor(injected, "getMe")},
+			wantErr:      injected,
+		},
+		{
+			name:         "fault for other functions",
+			interceptors: []Interceptor{FaultInterceptor(injected, "sendMessage")},
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			b := NewBot("token", BotOptions{APIEndpoint: server.URL, Interceptors: tt.interceptors})
+
+			me, err := b.GetMe()
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("GetMe() error = %v, want %v", err, tt.wantErr)
+			}
+			if me.UserName != tt.wantUsername {
+				t.Errorf("GetMe().Username = %q, want %q", me.UserName, tt.wantUsername)
+			}
+			if n := atomic.LoadInt32(&requests); n != tt.wantRequests {
+				t.Errorf("%d requests sent, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestFaultInterceptorDefaults(t *testing.T) {
+	var sent bool
+	next := func(context.Context, *Call) ([]byte, error) {
+		sent = true
+		return nil, nil
+	}
+
+	_, err := FaultInterceptor(nil)(context.Background(), &Call{Function: "sendMessage"}, next)
+	if err == nil || sent {
+		t.Errorf("FaultInterceptor(nil) = %v after sending %v, want an error without sending", err, sent)
+	}
+}
+
+func TestRedactTokenInterceptor(t *testing.T) {
+	token := "123456:secret"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	endpoint := server.URL
+	server.Close()
+
+	b := NewBot(token, BotOptions{APIEndpoint: endpoint, Interceptors: []Interceptor{RedactTokenInterceptor(token)}})
+
+	_, err := b.SendMessage(entity.MessageEnvelop{ChatID: "1", Text: "hello"})
+	if err == nil {
+		t.Fatal("SendMessage() to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), token) || !strings.Contains(err.Error(), "<redacted>") {
+		t.Errorf("error = %q, want the token redacted", err)
+	}
+
+	// the original error stays available.
+	unwrapped := errors.Unwrap(err)
+	if unwrapped == nil || !strings.Contains(unwrapped.Error(), token) {
+		t.Errorf("Unwrap() = %v, want the original error", unwrapped)
+	}
+
+	notFound := &APIError{ErrorCode: http.StatusNotFound, Description: "Not Found: bot" + token}
+	_, err = RedactTokenInterceptor(token)(context.Background(), &Call{}, func(context.Context, *Call) ([]byte, error) {
+		return nil, notFound
+	})
+	if apiErr, ok := AsAPIError(err); !ok || apiErr != notFound || strings.Contains(err.Error(), token) {
+		t.Errorf("error = %v, want the redacted api error", err)
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/roskee/gotbot/router"
 )
 
 // Level is the level of the log generated.
 // It can be one of `Debug`, `Info`, `Warn`, or `Error`
 type Level string
 
+// Fields are the key value pairs attached to a log message.
 type Fields map[string]any
 
 const (
@@ -68,3 +71,28 @@ func (d *JSONLogger) formatLog(level Level, msg string, fields map[string]any) {
 
 	fmt.Println(string(js))
 }
+
+// RouterLogger adapts logger to the Logger used by the router, conversation and session packages.
+func RouterLogger(logger Logger) router.Logger {
+	return routerLogger{logger: logger}
+}
+
+type routerLogger struct {
+	logger Logger
+}
+
+func (l routerLogger) Debug(msg string, fields map[string]any) {
+	l.logger.Debug(msg, fields)
+}
+
+func (l routerLogger) Info(msg string, fields map[string]any) {
+	l.logger.Info(msg, fields)
+}
+
+func (l routerLogger) Warn(msg string, fields map[string]any) {
+	l.logger.Warn(msg, fields)
+}
+
+func (l routerLogger) Error(msg string, fields map[string]any) {
+	l.logger.Error(msg, fields)
+}
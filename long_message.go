@@ -0,0 +1,118 @@
+package gotbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/format"
+	"github.com/roskee/gotbot/render"
+)
+
+func (b *bot) SendLongMessage(msg entity.MessageEnvelop) ([]entity.Message, error) {
+	return b.SendLongMessageContext(context.Background(), msg)
+}
+
+// SendLongMessageContext is the same as SendLongMessage but carries ctx to the request.
+func (b *bot) SendLongMessageContext(ctx context.Context, msg entity.MessageEnvelop) ([]entity.Message, error) {
+	return b.SendLongMessageAnyContext(ctx, MessageText, msg)
+}
+
+func (b *bot) SendLongMessageAny(msgType MessageType, msg entity.MessageEnvelop) ([]entity.Message, error) {
+	return b.SendLongMessageAnyContext(context.Background(), msgType, msg)
+}
+
+// SendLongMessageAnyContext is the same as SendLongMessageAny but carries ctx to the request.
+func (b *bot) SendLongMessageAnyContext(ctx context.Context, msgType MessageType, msg entity.MessageEnvelop) ([]entity.Message, error) {
+	text, entities, limit := msg.Caption, msg.CaptionEntities, int64(format.MaxCaptionLength)
+	if msgType == MessageText {
+		text, entities, limit = msg.Text, msg.Entities, format.MaxTextLength
+	}
+
+	// formatted text is never longer than its markup, so text that fits is sent as it is.
+	if format.Len(text) <= limit {
+		return b.sendChunks(ctx, msgType, msg, nil)
+	}
+
+	text, entities, err := parseText(text, entities, msg.ParseMode)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []format.Chunk
+	if msgType == MessageText {
+		chunks = format.Split(text, entities, format.MaxTextLength)
+		if len(chunks) == 0 {
+			return nil, errors.New("the text of the message is blank")
+		}
+		msg.Text, msg.Entities = chunks[0].Text, chunks[0].Entities
+	} else {
+		// the markup of a caption may be too long while its text fits, leaving no tail.
+		head, tail := format.Cut(text, entities, format.MaxCaptionLength)
+		msg.Caption, msg.CaptionEntities = head.Text, head.Entities
+		chunks = []format.Chunk{head}
+		if strings.TrimSpace(tail.Text) != "" {
+			chunks = append(chunks, format.Split(tail.Text, tail.Entities, format.MaxTextLength)...)
+		}
+	}
+	msg.ParseMode = ""
+
+	return b.sendChunks(ctx, msgType, msg, chunks[1:])
+}
+
+// sendChunks sends msg followed by a text message for every chunk of rest.
+// The reply markup of msg is moved to the last message.
+func (b *bot) sendChunks(ctx context.Context, msgType MessageType, msg entity.MessageEnvelop, rest []format.Chunk) ([]entity.Message, error) {
+	markup := msg.ReplyMarkup
+	if len(rest) != 0 {
+		msg.ReplyMarkup = entity.ReplyMarkup{}
+	}
+
+	messages := make([]entity.Message, 0, len(rest)+1)
+
+	var sent entity.Message
+	if err := b.SendMessageAnyContext(ctx, msgType, msg, &sent); err != nil {
+		return messages, err
+	}
+	messages = append(messages, sent)
+
+	for i, chunk := range rest {
+		next := entity.MessageEnvelop{
+			ChatID:                msg.ChatID,
+			MessageThreadID:       msg.MessageThreadID,
+			Text:                  chunk.Text,
+			Entities:              chunk.Entities,
+			DisableWebPagePreview: msg.DisableWebPagePreview,
+			DisableNotification:   msg.DisableNotification,
+			ProtectContent:        msg.ProtectContent,
+		}
+		if i == len(rest)-1 {
+			next.ReplyMarkup = markup
+		}
+
+		var sent entity.Message
+		if err := b.SendMessageAnyContext(ctx, MessageText, next, &sent); err != nil {
+			return messages, err
+		}
+		messages = append(messages, sent)
+	}
+
+	return messages, nil
+}
+
+// parseText returns the plain text and the entities of text formatted with parseMode.
+func parseText(text string, entities []entity.MessageEntity, parseMode string) (string, []entity.MessageEntity, error) {
+	switch parseMode {
+	case "":
+		return text, entities, nil
+	case entity.ParseModeMarkdownV2:
+		return render.FromMarkdownV2(text)
+	case entity.ParseModeHTML:
+		return render.FromHTML(text)
+	}
+
+	// the legacy Markdown mode has no rules for nested or escaped markup to parse it back reliably.
+	return "", nil, fmt.Errorf("long messages can't be split with the %s parse mode", parseMode)
+}
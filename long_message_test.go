@@ -0,0 +1,155 @@
+package gotbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/format"
+)
+
+// sentMessage is a message received by messageServer.
+type sentMessage struct {
+	method   string
+	text     string
+	entities []entity.MessageEntity
+	markup   string
+	mode     string
+}
+
+// messageServer is a stub server that records the messages sent to it.
+type messageServer struct {
+	mu   sync.Mutex
+	sent []sentMessage
+}
+
+func (s *messageServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	message := sentMessage{
+		method: r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:],
+		text:   r.FormValue("text"),
+		markup: r.FormValue("reply_markup"),
+		mode:   r.FormValue("parse_mode"),
+	}
+	entities := r.FormValue("entities")
+	if message.method != string(MessageText) {
+		message.text, entities = r.FormValue("caption"), r.FormValue("caption_entities")
+	}
+	if entities != "" {
+		_ = json.Unmarshal([]byte(entities), &message.entities)
+	}
+
+	s.mu.Lock()
+	s.sent = append(s.sent, message)
+	id := len(s.sent)
+	s.mu.Unlock()
+
+	_, _ = fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d}}`, id)
+}
+
+func TestSendLongMessage(t *testing.T) {
+	markup := entity.ReplyMarkup{InlineKeyboardMarkup: &entity.InlineKeyboardMarkup{
+		InlineKeyboard: [][]entity.InlineKeyboardButton{{{Text: "ok", CallbackData: "ok"}}},
+	}}
+	paragraph := strings.Repeat("🎉", 1500)
+
+	tests := []struct {
+		name    string
+		msgType MessageType
+		msg     entity.MessageEnvelop
+		want    []sentMessage
+		wantErr bool
+	}{
+		{
+			name:    "fits",
+			msgType: MessageText,
+			msg:     entity.MessageEnvelop{Text: "*hi*", ParseMode: entity.ParseModeMarkdownV2},
+			want:    []sentMessage{{method: "sendMessage", text: "*hi*", mode: entity.ParseModeMarkdownV2}},
+		},
+		{
+			name:    "split at paragraphs in UTF-16 code units",
+			msgType: MessageText,
+			msg:     entity.MessageEnvelop{Text: "<b>" + paragraph + "</b>\n\n" + paragraph, ParseMode: entity.ParseModeHTML},
+			want: []sentMessage{
+				{method: "sendMessage", text: paragraph, entities: []entity.MessageEntity{{Type: entity.EntityBold, Offset: 0, Length: 3000}}},
+				{method: "sendMessage", text: paragraph},
+			},
+		},
+		{
+			name:    "caption markup too long for text that fits",
+			msgType: MessagePhoto,
+			msg:     entity.MessageEnvelop{Photo: &entity.FileEnvelop{Path: "photo_id"}, Caption: "<b>" + strings.Repeat("a", 1020) + "</b>", ParseMode: entity.ParseModeHTML},
+			want: []sentMessage{
+				{method: "sendPhoto", text: strings.Repeat("a", 1020), entities: []entity.MessageEntity{{Type: entity.EntityBold, Offset: 0, Length: 1020}}},
+			},
+		},
+		{
+			name:    "caption followed by text",
+			msgType: MessagePhoto,
+			msg:     entity.MessageEnvelop{Photo: &entity.FileEnvelop{Path: "photo_id"}, Caption: strings.Repeat("a", 1000) + " " + strings.Repeat("b", 100)},
+			want: []sentMessage{
+				{method: "sendPhoto", text: strings.Repeat("a", 1000)},
+				{method: "sendMessage", text: strings.Repeat("b", 100)},
+			},
+		},
+		{
+			name:    "legacy markdown",
+			msgType: MessageText,
+			msg:     entity.MessageEnvelop{Text: strings.Repeat("a ", 3000), ParseMode: entity.ParseModeMarkdown},
+			wantErr: true,
+		},
+		{
+			name:    "blank",
+			msgType: MessageText,
+			msg:     entity.MessageEnvelop{Text: strings.Repeat(" ", 5000)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &messageServer{}
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+
+			tt.msg.ChatID = "1"
+			tt.msg.ReplyMarkup = markup
+			messages, err := NewBot("token", BotOptions{APIEndpoint: httpServer.URL}).SendLongMessageAny(tt.msgType, tt.msg)
+			if tt.wantErr {
+				if err == nil || len(server.sent) != 0 {
+					t.Fatalf("SendLongMessageAny() error = %v after %d messages, want an error before sending", err, len(server.sent))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendLongMessageAny() error = %v", err)
+			}
+			if len(messages) != len(tt.want) {
+				t.Fatalf("SendLongMessageAny() returned %d messages, want %d", len(messages), len(tt.want))
+			}
+
+			markupJSON, _ := json.Marshal(markup)
+			for i := range tt.want {
+				if i == len(tt.want)-1 {
+					tt.want[i].markup = string(markupJSON)
+				}
+				if !reflect.DeepEqual(server.sent[i], tt.want[i]) {
+					t.Errorf("message %d = %+v, want %+v", i, server.sent[i], tt.want[i])
+				}
+				if format.Len(server.sent[i].text) > format.MaxTextLength {
+					t.Errorf("message %d is %d code units long", i, format.Len(server.sent[i].text))
+				}
+			}
+		})
+	}
+}
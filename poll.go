@@ -0,0 +1,222 @@
+package gotbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/envelop"
+)
+
+// ErrClientTimeout is returned when the timeout of the http client of the bot
+// doesn't leave enough time for a long poll request to complete.
+var ErrClientTimeout = errors.New("http client timeout is shorter than the long polling timeout")
+
+// pollErrorDelay is the minimum delay before polling again after a failed poll.
+const pollErrorDelay = time.Second
+
+// pollPendingDelay is the longest delay before polling again when all the updates received
+// are still being handled. Polling earlier would only receive them again.
+const pollPendingDelay = time.Second
+
+// PollOptions hold the options for polling updates.
+type PollOptions struct {
+	// Interval is the time to wait before every request for new updates.
+	// It is mostly useful for short polling and can be left empty when Timeout is set.
+	Interval time.Duration
+	// Timeout is the time the telegram server holds a request open until an update arrives.
+	// It is rounded down to whole seconds. Zero means short polling.
+	//
+	// The timeout of BotOptions.Client must be longer than Timeout.
+	Timeout time.Duration
+	// Limit limits the number of updates to be retrieved by a single request.
+	// Values between 1-100 are accepted. Defaults to 100.
+	Limit int64
+	// AllowedUpdates is the list of the update types you want your bot to receive.
+	// All update types except chat_member are received if it is empty.
+	//
+	// eg: entity.UpdateMessage, entity.UpdateEditedMessage, etc
+	AllowedUpdates []string
+}
+
+// validate checks options against the options of the bot.
+func (o PollOptions) validate(botOptions BotOptions) error {
+	if o.Timeout > 0 && botOptions.Client.Timeout > 0 && botOptions.Client.Timeout <= o.Timeout {
+		return fmt.Errorf("%w: client timeout = %s, polling timeout = %s",
+			ErrClientTimeout, botOptions.Client.Timeout, o.Timeout)
+	}
+
+	if o.Limit < 0 || o.Limit > 100 {
+		return fmt.Errorf("invalid polling limit: %d", o.Limit)
+	}
+
+	return nil
+}
+
+// PollWithOptions is the same as Poll but uses long polling as configured by options.
+//
+// It returns on failure only
+func (b *bot) PollWithOptions(options PollOptions, config entity.UpdateConfig) error {
+	return b.PollContext(context.Background(), options, config)
+}
+
+// PollContext is the same as PollWithOptions but stops polling once ctx is done.
+// Running update handlers are given BotOptions.ShutdownTimeout to complete
+// and the updates that were handled are confirmed to the telegram server before it returns.
+//
+// It returns nil if polling was stopped because ctx is done.
+func (b *bot) PollContext(ctx context.Context, options PollOptions, config entity.UpdateConfig) error {
+	if err := options.validate(b.options); err != nil {
+		b.options.Logger.Error("invalid polling options", Fields{
+			"error": err.Error(),
+		})
+
+		return err
+	}
+
+	b.options.Logger.Info("deleting webhook if exists", Fields{})
+
+	_, err := b.DeleteWebhookContext(ctx, envelop.DeleteWebhookEnvelop{})
+	if err != nil {
+		b.options.Logger.Error("error while deleting webhook", Fields{
+			"error": err.Error(),
+		})
+
+		return err
+	}
+
+	b.options.Logger.Info("Polling started", Fields{
+		"interval":        options.Interval.String(),
+		"timeout":         options.Timeout.String(),
+		"limit":           options.Limit,
+		"allowed_updates": options.AllowedUpdates,
+	})
+
+	dispatcher := newDispatcher(b, config)
+	b.poll(ctx, options, dispatcher)
+
+	return b.stopPolling(dispatcher)
+}
+
+// poll fetches updates and passes them to dispatcher until ctx is done.
+//
+// Only the updates dispatcher has handled are confirmed, so no update is lost when polling stops
+// with updates still queued. The telegram server sends the other ones again, which dispatcher skips.
+func (b *bot) poll(ctx context.Context, options PollOptions, dispatcher *dispatcher) {
+	for {
+		if err := wait(ctx, options.Interval); err != nil {
+			return
+		}
+
+		progress := dispatcher.progress()
+		updates, err := b.GetUpdatesContext(ctx, envelop.GetUpdatesEnvelop{
+			Offset:         dispatcher.offset(),
+			Limit:          options.Limit,
+			Timeout:        int64(options.Timeout / time.Second),
+			AllowedUpdates: options.AllowedUpdates,
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			b.options.Logger.Error("Error while polling", Fields{
+				"error": err.Error(),
+			})
+
+			if options.Interval < pollErrorDelay {
+				if err := wait(ctx, pollErrorDelay-options.Interval); err != nil {
+					return
+				}
+			}
+
+			continue
+		}
+
+		dispatched := false
+		for _, update := range updates {
+			ok, err := dispatcher.dispatch(ctx, update)
+			if err != nil {
+				return
+			}
+			dispatched = dispatched || ok
+		}
+
+		// the server answers right away while there are unconfirmed updates,
+		// so polling again before one of them is handled would only receive them again.
+		if len(updates) != 0 && !dispatched {
+			timer := time.NewTimer(pollPendingDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-progress:
+			case <-timer.C:
+			}
+			timer.Stop()
+		}
+	}
+}
+
+// stopPolling drains the running handlers of dispatcher
+// and confirms the handled updates to the telegram server.
+func (b *bot) stopPolling(dispatcher *dispatcher) error {
+	b.options.Logger.Info("stopping polling", Fields{
+		"timeout": b.options.ShutdownTimeout.String(),
+	})
+
+	if !dispatcher.shutdown(b.options.ShutdownTimeout) {
+		b.options.Logger.Warn("update handlers didn't complete in time", Fields{})
+	}
+
+	offset := dispatcher.offset()
+	if offset == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.options.ShutdownTimeout)
+	defer cancel()
+
+	// updates before offset are confirmed by requesting the ones after them.
+	_, err := b.GetUpdatesContext(ctx, envelop.GetUpdatesEnvelop{
+		Offset: offset,
+		Limit:  1,
+	})
+	if err != nil {
+		b.options.Logger.Error("error while confirming handled updates", Fields{
+			"offset": offset,
+			"error":  err.Error(),
+		})
+
+		return err
+	}
+
+	b.options.Logger.Info("Polling stopped", Fields{
+		"offset": offset,
+	})
+
+	return nil
+}
+
+// GetUpdates is the implementation of the builtin getUpdates function of the bot.
+func (b *bot) GetUpdates(options envelop.GetUpdatesEnvelop) ([]entity.Update, error) {
+	return b.GetUpdatesContext(context.Background(), options)
+}
+
+// GetUpdatesContext is the same as GetUpdates but carries ctx to the request.
+func (b *bot) GetUpdatesContext(ctx context.Context, options envelop.GetUpdatesEnvelop) ([]entity.Update, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "getUpdates", func() (io.Reader, BodyOptions, error) {
+		return GetJSONBody(options)
+	}, SetApplicationJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []entity.Update
+
+	return updates, json.Unmarshal(res, &updates)
+}
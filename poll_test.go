@@ -0,0 +1,248 @@
+package gotbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/envelop"
+)
+
+func TestPollOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       PollOptions
+		clientTimeout time.Duration
+		wantErr       bool
+		wantTimeout   bool
+	}{
+		{name: "defaults", options: PollOptions{}, clientTimeout: 30 * time.Second},
+		{name: "longer client timeout", options: PollOptions{Timeout: 30 * time.Second}, clientTimeout: 40 * time.Second},
+		{name: "no client timeout", options: PollOptions{Timeout: time.Hour}},
+		{name: "short polling", options: PollOptions{Interval: time.Second}, clientTimeout: time.Second},
+		{name: "shorter client timeout", options: PollOptions{Timeout: time.Minute}, clientTimeout: 30 * time.Second, wantErr: true, wantTimeout: true},
+		{name: "equal timeouts", options: PollOptions{Timeout: 30 * time.Second}, clientTimeout: 30 * time.Second, wantErr: true, wantTimeout: true},
+		{name: "negative limit", options: PollOptions{Limit: -1}, wantErr: true},
+		{name: "limit above 100", options: PollOptions{Limit: 101}, wantErr: true},
+		{name: "limit 1", options: PollOptions{Limit: 1}},
+		{name: "limit 100", options: PollOptions{Limit: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.validate(BotOptions{Client: http.Client{Timeout: tt.clientTimeout}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, want error %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrClientTimeout) != tt.wantTimeout {
+				t.Errorf("validate() error = %v, want ErrClientTimeout %v", err, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestPollContextConfirmsHandledUpdatesOnly(t *testing.T) {
+	server := newPollServer(10)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handled := newHandledUpdates()
+	logger := &stopLogger{stopping: make(chan struct{})}
+	b := NewBot("token", BotOptions{APIEndpoint: server.URL, ShutdownTimeout: 5 * time.Second, Logger: logger})
+
+	err := b.PollContext(ctx, PollOptions{}, entity.UpdateConfig{
+		OnUpdate: func(update entity.Update) {
+			if update.UpdateID == 1 {
+				// poll again with the rest of the batch queued, then stop polling before it is handled.
+				for deadline := time.Now().Add(5 * time.Second); len(server.offsets()) < 2 && time.Now().Before(deadline); {
+					time.Sleep(time.Millisecond)
+				}
+				cancel()
+				<-logger.stopping
+				time.Sleep(10 * time.Millisecond)
+			}
+			handled.add(update.UpdateID)
+		},
+	})
+	if err != nil {
+		t.Fatalf("PollContext() error = %v", err)
+	}
+
+	offsets := server.offsets()
+	if last := offsets[len(offsets)-1]; last < 2 {
+		t.Errorf("last offset = %d, want the handled update 1 confirmed", last)
+	}
+	handled.checkConfirmed(t, offsets)
+}
+
+func TestPollContextWithConcurrency(t *testing.T) {
+	server := newPollServer(40)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handled := newHandledUpdates()
+	logger := &stopLogger{stopping: make(chan struct{})}
+	b := NewBot("token", BotOptions{APIEndpoint: server.URL, Concurrency: 4, ShutdownTimeout: 5 * time.Second, Logger: logger})
+
+	err := b.PollContext(ctx, PollOptions{}, entity.UpdateConfig{
+		OnUpdate: func(update entity.Update) {
+			if update.UpdateID == 1 {
+				// the server sends the batch again while update 1 is running.
+				for deadline := time.Now().Add(5 * time.Second); len(server.offsets()) < 3 && time.Now().Before(deadline); {
+					time.Sleep(time.Millisecond)
+				}
+				cancel()
+				<-logger.stopping
+			}
+			time.Sleep(20 * time.Millisecond)
+			handled.add(update.UpdateID)
+		},
+	})
+	if err != nil {
+		t.Fatalf("PollContext() error = %v", err)
+	}
+
+	handled.mu.Lock()
+	if len(handled.count) == len(server.updates) {
+		t.Errorf("all %d updates handled, want some left queued when polling stopped", len(handled.count))
+	}
+	handled.mu.Unlock()
+
+	handled.checkConfirmed(t, server.offsets())
+}
+
+// stopLogger closes stopping when polling is being stopped.
+type stopLogger struct {
+	stopping chan struct{}
+}
+
+func (*stopLogger) Debug(string, Fields) {}
+func (l *stopLogger) Info(msg string, _ Fields) {
+	if msg == "stopping polling" {
+		close(l.stopping)
+	}
+}
+func (*stopLogger) Warn(string, Fields)  {}
+func (*stopLogger) Error(string, Fields) {}
+
+// pollServer is a stub of the telegram server that serves a fixed list of message updates
+// and records the offsets of the getUpdates requests it receives.
+type pollServer struct {
+	*httptest.Server
+
+	updates []entity.Update
+
+	mu       sync.Mutex
+	received []int64
+}
+
+// newPollServer starts a pollServer serving n updates with the ids 1 to n in n different chats.
+func newPollServer(n int) *pollServer {
+	s := &pollServer{}
+	for i := 1; i <= n; i++ {
+		s.updates = append(s.updates, entity.Update{
+			UpdateID: int64(i),
+			Message:  &entity.Message{MessageID: int64(i), Chat: &entity.Chat{ID: int64(i)}},
+		})
+	}
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+func (s *pollServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/deleteWebhook"):
+		_, _ = fmt.Fprint(w, `{"ok":true,"result":true}`)
+	case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+		var options envelop.GetUpdatesEnvelop
+		if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		s.received = append(s.received, options.Offset)
+		s.mu.Unlock()
+
+		limit := options.Limit
+		if limit == 0 {
+			limit = 100
+		}
+		updates := []entity.Update{}
+		for _, update := range s.updates {
+			if update.UpdateID >= options.Offset && int64(len(updates)) < limit {
+				updates = append(updates, update)
+			}
+		}
+
+		result, _ := json.Marshal(updates)
+		_, _ = fmt.Fprintf(w, `{"ok":true,"result":%s}`, result)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// offsets returns the offsets received so far.
+func (s *pollServer) offsets() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]int64(nil), s.received...)
+}
+
+// handledUpdates counts how many times each update is handled.
+type handledUpdates struct {
+	mu    sync.Mutex
+	count map[int64]int
+}
+
+func newHandledUpdates() *handledUpdates {
+	return &handledUpdates{count: map[int64]int{}}
+}
+
+func (h *handledUpdates) add(updateID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count[updateID]++
+}
+
+// checkConfirmed checks that every update confirmed by offsets was handled exactly once
+// and that no other update was handled more than once.
+func (h *handledUpdates) checkConfirmed(t *testing.T, offsets []int64) {
+	t.Helper()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var confirmed int64
+	for _, offset := range offsets {
+		if offset > confirmed {
+			confirmed = offset
+		}
+	}
+
+	for updateID := int64(1); updateID < confirmed; updateID++ {
+		if h.count[updateID] == 0 {
+			t.Errorf("update %d was confirmed but not handled", updateID)
+		}
+	}
+	for updateID, count := range h.count {
+		if count > 1 {
+			t.Errorf("update %d was handled %d times", updateID, count)
+		}
+	}
+}
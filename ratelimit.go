@@ -0,0 +1,304 @@
+package gotbot
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default limits of the telegram server for outgoing messages.
+var (
+	// DefaultGlobalRate is the maximum number of messages a bot can send per second over all chats.
+	DefaultGlobalRate = Rate{Count: 30, Per: time.Second}
+	// DefaultPrivateChatRate is the maximum number of messages a bot can send to a single private chat.
+	DefaultPrivateChatRate = Rate{Count: 1, Per: time.Second}
+	// DefaultGroupChatRate is the maximum number of messages a bot can send to a single group or channel.
+	DefaultGroupChatRate = Rate{Count: 20, Per: time.Minute}
+)
+
+// Rate is a limit of Count events Per duration.
+// Up to Count events are allowed in a burst after which events are spread evenly.
+type Rate struct {
+	Count int
+	Per   time.Duration
+}
+
+// Clock is the source of time for the RateLimiter.
+// It can be replaced with a fake clock for tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// RateLimiterOptions hold the options for the RateLimiter.
+// Zero values are replaced by their defaults.
+type RateLimiterOptions struct {
+	// Global is the limit over all chats. Defaults to DefaultGlobalRate.
+	Global Rate
+	// PrivateChat is the limit for a single private chat. Defaults to DefaultPrivateChatRate.
+	PrivateChat Rate
+	// GroupChat is the limit for a single group, supergroup or channel. Defaults to DefaultGroupChatRate.
+	GroupChat Rate
+	// Clock is the source of time. Defaults to the system clock.
+	Clock Clock
+}
+
+// RateLimiterStats is a snapshot of the state of a RateLimiter.
+type RateLimiterStats struct {
+	// QueueDepth is the number of requests currently waiting.
+	QueueDepth int
+	// Waited is the number of requests that had to wait so far.
+	Waited int64
+	// TotalWait is the sum of the wait times of all requests so far.
+	TotalWait time.Duration
+	// MaxWait is the longest wait time of a single request so far.
+	MaxWait time.Duration
+}
+
+// RateLimiter queues outgoing requests so that the bot stays within the limits of the telegram server.
+// Requests are limited per chat and globally.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	options RateLimiterOptions
+
+	mu         sync.Mutex
+	global     gcra
+	chats      map[string]*gcra
+	cleanedAt  time.Time
+	queueDepth map[string]int
+	stats      RateLimiterStats
+}
+
+// NewRateLimiter returns a new RateLimiter with the given options.
+func NewRateLimiter(options RateLimiterOptions) *RateLimiter {
+	if options.Global.Count == 0 {
+		options.Global = DefaultGlobalRate
+	}
+	if options.PrivateChat.Count == 0 {
+		options.PrivateChat = DefaultPrivateChatRate
+	}
+	if options.GroupChat.Count == 0 {
+		options.GroupChat = DefaultGroupChatRate
+	}
+	if options.Clock == nil {
+		options.Clock = systemClock{}
+	}
+
+	return &RateLimiter{
+		options:    options,
+		chats:      map[string]*gcra{},
+		queueDepth: map[string]int{},
+	}
+}
+
+// Wait blocks until a message can be sent to chatID.
+// It returns early with the error of ctx if ctx is done before that.
+func (l *RateLimiter) Wait(ctx context.Context, chatID string) error {
+	l.enqueue(chatID)
+	defer l.dequeue(chatID)
+
+	start := l.options.Clock.Now()
+
+	// the chat is reserved first so that a chat with a long queue doesn't hold global slots.
+	if err := l.waitUntil(ctx, l.reserve(chatID)); err != nil {
+		l.release(chatID)
+		return err
+	}
+	if err := l.waitUntil(ctx, l.reserve("")); err != nil {
+		l.release("")
+		l.release(chatID)
+		return err
+	}
+
+	l.record(l.options.Clock.Now().Sub(start))
+
+	return nil
+}
+
+// Stats returns the current state of the limiter.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+// ChatQueueDepth returns the number of requests currently waiting for chatID.
+func (l *RateLimiter) ChatQueueDepth(chatID string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.queueDepth[chatID]
+}
+
+func (l *RateLimiter) enqueue(chatID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.QueueDepth++
+	l.queueDepth[chatID]++
+}
+
+func (l *RateLimiter) dequeue(chatID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.QueueDepth--
+	l.queueDepth[chatID]--
+	if l.queueDepth[chatID] == 0 {
+		delete(l.queueDepth, chatID)
+	}
+}
+
+func (l *RateLimiter) record(waited time.Duration) {
+	if waited <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.Waited++
+	l.stats.TotalWait += waited
+	if waited > l.stats.MaxWait {
+		l.stats.MaxWait = waited
+	}
+}
+
+// reserve reserves the next slot of chatID and returns its time.
+// An empty chatID reserves a global slot.
+func (l *RateLimiter) reserve(chatID string) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.options.Clock.Now()
+
+	if chatID == "" {
+		return l.global.reserve(now, l.options.Global)
+	}
+
+	limit, ok := l.chats[chatID]
+	if !ok {
+		l.cleanup(now)
+		limit = &gcra{}
+		l.chats[chatID] = limit
+	}
+
+	return limit.reserve(now, l.rateOf(chatID))
+}
+
+// release gives back a slot of chatID reserved by a request that was cancelled before it was sent.
+func (l *RateLimiter) release(chatID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if chatID == "" {
+		l.global.release(l.options.Global)
+		return
+	}
+
+	if limit, ok := l.chats[chatID]; ok {
+		limit.release(l.rateOf(chatID))
+	}
+}
+
+// cleanup removes chats whose limits have fully recovered.
+// It runs at most once per minute.
+func (l *RateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.cleanedAt) < time.Minute {
+		return
+	}
+	l.cleanedAt = now
+
+	for chatID, limit := range l.chats {
+		if !limit.tat.After(now) {
+			delete(l.chats, chatID)
+		}
+	}
+}
+
+func (l *RateLimiter) rateOf(chatID string) Rate {
+	// private chats have the positive id of the user,
+	// groups and channels have negative ids or are referred to by their @username.
+	if strings.HasPrefix(chatID, "-") || strings.HasPrefix(chatID, "@") {
+		return l.options.GroupChat
+	}
+
+	return l.options.PrivateChat
+}
+
+func (l *RateLimiter) waitUntil(ctx context.Context, at time.Time) error {
+	d := at.Sub(l.options.Clock.Now())
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.options.Clock.After(d):
+		return nil
+	}
+}
+
+// isRateLimited reports whether calls to the telegram function count towards the message limits.
+// Chat actions like the typing indicator don't count.
+func isRateLimited(function string) bool {
+	if function == string(MessageChatAction) {
+		return false
+	}
+
+	for _, prefix := range []string{"send", "edit", "forward", "copy", "stop"} {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gcra is a generic cell rate algorithm limiter.
+// tat is the theoretical arrival time of the next event.
+type gcra struct {
+	tat time.Time
+}
+
+// reserve reserves the next free slot at or after now and returns its time.
+func (g *gcra) reserve(now time.Time, rate Rate) time.Time {
+	interval := rate.Per / time.Duration(rate.Count)
+	tolerance := rate.Per - interval
+
+	tat := g.tat
+	if tat.Before(now) {
+		tat = now
+	}
+
+	at := tat.Add(-tolerance)
+	if at.Before(now) {
+		at = now
+	}
+
+	g.tat = tat.Add(interval)
+
+	return at
+}
+
+// release gives back the last slot reserved with rate.
+// Slots reserved after it move up by one interval instead.
+func (g *gcra) release(rate Rate) {
+	g.tat = g.tat.Add(-rate.Per / time.Duration(rate.Count))
+}
@@ -0,0 +1,176 @@
+package gotbot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that only moves when it is advanced.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), c: ch})
+
+	return ch
+}
+
+// Advance moves the clock forward by d and fires the waiters that are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	var pending []fakeWaiter
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+		} else {
+			w.c <- c.now
+		}
+	}
+	c.waiters = pending
+}
+
+// waitForWaiters blocks until n goroutines wait on the clock.
+func (c *fakeClock) waitForWaiters(t *testing.T, n int) {
+	t.Helper()
+
+	for i := 0; i < 1000; i++ {
+		c.mu.Lock()
+		count := len(c.waiters)
+		c.mu.Unlock()
+		if count >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiters", n)
+}
+
+func TestGCRAReserve(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rate := Rate{Count: 3, Per: 300 * time.Millisecond}
+
+	var g gcra
+	want := []time.Duration{0, 0, 0, 100 * time.Millisecond, 200 * time.Millisecond}
+	for i, offset := range want {
+		if at := g.reserve(start, rate); !at.Equal(start.Add(offset)) {
+			t.Errorf("reserve() #%d = +%v; want +%v", i, at.Sub(start), offset)
+		}
+	}
+
+	// the burst recovers once the limit is idle for a whole period.
+	later := start.Add(time.Second)
+	for i := 0; i < 3; i++ {
+		if at := g.reserve(later, rate); !at.Equal(later) {
+			t.Errorf("reserve() after recovery #%d = +%v; want +0s", i, at.Sub(later))
+		}
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewRateLimiter(RateLimiterOptions{Clock: clock})
+
+	if err := limiter.Wait(context.Background(), "1"); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- limiter.Wait(context.Background(), "1") }()
+
+	clock.waitForWaiters(t, 1)
+	if depth := limiter.ChatQueueDepth("1"); depth != 1 {
+		t.Errorf("ChatQueueDepth() = %d; want 1", depth)
+	}
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Wait() returned before the private chat limit recovered")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+
+	stats := limiter.Stats()
+	if stats.Waited != 1 || stats.MaxWait != time.Second || stats.QueueDepth != 0 {
+		t.Errorf("Stats() = %+v; want one wait of 1s and an empty queue", stats)
+	}
+}
+
+func TestRateLimiterCancelReleasesSlot(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewRateLimiter(RateLimiterOptions{Clock: clock})
+
+	if err := limiter.Wait(context.Background(), "1"); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- limiter.Wait(ctx, "1") }()
+
+	clock.waitForWaiters(t, 1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() = %v; want context.Canceled", err)
+	}
+
+	// without giving the cancelled slot back, the next message would have to wait 2s.
+	clock.Advance(time.Second)
+	if at := limiter.reserve("1"); !at.Equal(clock.Now()) {
+		t.Errorf("next slot is at +%v; want now", at.Sub(clock.Now()))
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	tests := map[string]bool{
+		"sendMessage":         true,
+		"sendPhoto":           true,
+		"editMessageText":     true,
+		"forwardMessage":      true,
+		"copyMessage":         true,
+		"stopPoll":            true,
+		"sendChatAction":      false,
+		"getUpdates":          false,
+		"answerCallbackQuery": false,
+	}
+
+	for function, want := range tests {
+		if got := isRateLimited(function); got != want {
+			t.Errorf("isRateLimited(%q) = %v; want %v", function, got, want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Package render writes text for the MarkdownV2, Markdown and HTML parse modes
+// and converts formatted text between message entities, MarkdownV2 and HTML.
+//
+// User supplied text must always be escaped for the parse mode of the message,
+// otherwise the telegram server rejects the message or formats it unexpectedly.
+package render
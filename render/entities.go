@@ -0,0 +1,303 @@
+package render
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// ToMarkdownV2 renders text with its entities as MarkdownV2.
+// Entities that the telegram server detects on its own, like mentions, urls and bot commands,
+// are written as plain text.
+func ToMarkdownV2(text string, entities []entity.MessageEntity) string {
+	return renderEntities(text, entities, markdownV2Writer{})
+}
+
+// ToHTML renders text with its entities as HTML.
+// Entities that the telegram server detects on its own, like mentions, urls and bot commands,
+// are written as plain text.
+func ToHTML(text string, entities []entity.MessageEntity) string {
+	return renderEntities(text, entities, htmlWriter{})
+}
+
+// MessageToMarkdownV2 renders the text or, if it has no text, the caption of message as MarkdownV2.
+func MessageToMarkdownV2(message *entity.Message) string {
+	if message.Text == "" {
+		return ToMarkdownV2(message.Caption, message.CaptionEntities)
+	}
+
+	return ToMarkdownV2(message.Text, message.Entities)
+}
+
+// MessageToHTML renders the text or, if it has no text, the caption of message as HTML.
+func MessageToHTML(message *entity.Message) string {
+	if message.Text == "" {
+		return ToHTML(message.Caption, message.CaptionEntities)
+	}
+
+	return ToHTML(message.Text, message.Entities)
+}
+
+// MarkdownV2ToHTML converts MarkdownV2 to HTML.
+func MarkdownV2ToHTML(s string) (string, error) {
+	text, entities, err := FromMarkdownV2(s)
+	if err != nil {
+		return "", err
+	}
+
+	return ToHTML(text, entities), nil
+}
+
+// HTMLToMarkdownV2 converts HTML to MarkdownV2.
+func HTMLToMarkdownV2(s string) (string, error) {
+	text, entities, err := FromHTML(s)
+	if err != nil {
+		return "", err
+	}
+
+	return ToMarkdownV2(text, entities), nil
+}
+
+// writer writes the parts of formatted text for a parse mode.
+type writer interface {
+	// text escapes plain text.
+	text(s string) string
+	// code escapes the text of code and code blocks.
+	code(s string) string
+	// wrap formats inner, which is already escaped, with e.
+	wrap(e entity.MessageEntity, inner string) string
+	// join joins two parts of formatted text.
+	join(left, right string) string
+}
+
+// node is an entity with the entities nested in it.
+type node struct {
+	entity     entity.MessageEntity
+	start, end int64
+	children   []*node
+}
+
+// renderEntities renders text with its entities with w.
+func renderEntities(text string, entities []entity.MessageEntity, w writer) string {
+	encoded := utf16.Encode([]rune(text))
+
+	return renderRange(encoded, 0, int64(len(encoded)), buildTree(entities, int64(len(encoded))), w)
+}
+
+// buildTree nests the formatting entities.
+// An entity overlapping the end of the entity it starts in is cut to it.
+func buildTree(entities []entity.MessageEntity, length int64) []*node {
+	var nodes []*node
+	for _, e := range entities {
+		if !formatting(e.Type) {
+			continue
+		}
+		start, end := clamp(e.Offset, length), clamp(e.Offset+e.Length, length)
+		if start < end {
+			nodes = append(nodes, &node{entity: e, start: start, end: end})
+		}
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].start != nodes[j].start {
+			return nodes[i].start < nodes[j].start
+		}
+		return nodes[i].end > nodes[j].end
+	})
+
+	var roots, stack []*node
+	for _, n := range nodes {
+		for len(stack) != 0 && stack[len(stack)-1].end <= n.start {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			roots = append(roots, n)
+		} else {
+			parent := stack[len(stack)-1]
+			if n.end > parent.end {
+				n.end = parent.end
+			}
+			parent.children = append(parent.children, n)
+		}
+		stack = append(stack, n)
+	}
+
+	return roots
+}
+
+func renderRange(encoded []uint16, start, end int64, nodes []*node, w writer) string {
+	var result string
+
+	position := start
+	for _, n := range nodes {
+		result = w.join(result, w.text(decode(encoded, position, n.start)))
+
+		var inner string
+		switch n.entity.Type {
+		case entity.EntityCode, entity.EntityPre:
+			// code can't hold other formatting.
+			inner = w.code(decode(encoded, n.start, n.end))
+		default:
+			inner = renderRange(encoded, n.start, n.end, n.children, w)
+		}
+		result = w.join(result, w.wrap(n.entity, inner))
+
+		position = n.end
+	}
+
+	return w.join(result, w.text(decode(encoded, position, end)))
+}
+
+func decode(encoded []uint16, start, end int64) string {
+	return string(utf16.Decode(encoded[start:end]))
+}
+
+// formatting reports whether entities of entityType are written by a parse mode.
+func formatting(entityType string) bool {
+	switch entityType {
+	case entity.EntityBold, entity.EntityItalic, entity.EntityUnderline, entity.EntityStrikethrough,
+		entity.EntitySpoiler, entity.EntityCode, entity.EntityPre, entity.EntityTextLink,
+		entity.EntityTextMention, entity.EntityCustomEmoji:
+		return true
+	}
+
+	return false
+}
+
+func clamp(value, max int64) int64 {
+	if value < 0 {
+		return 0
+	}
+	if value > max {
+		return max
+	}
+
+	return value
+}
+
+type markdownV2Writer struct{}
+
+func (markdownV2Writer) text(s string) string { return EscapeMarkdownV2(s) }
+func (markdownV2Writer) code(s string) string { return EscapeMarkdownV2Code(s) }
+
+func (w markdownV2Writer) wrap(e entity.MessageEntity, inner string) string {
+	switch e.Type {
+	case entity.EntityBold:
+		return "*" + inner + "*"
+	case entity.EntityItalic:
+		return w.join(w.join("_", inner), "_")
+	case entity.EntityUnderline:
+		return w.join(w.join("__", inner), "__")
+	case entity.EntityStrikethrough:
+		return "~" + inner + "~"
+	case entity.EntitySpoiler:
+		return "||" + inner + "||"
+	case entity.EntityCode:
+		return "`" + inner + "`"
+	case entity.EntityPre:
+		return "```" + e.Language + "\n" + inner + "```"
+	case entity.EntityTextLink:
+		return "[" + inner + "](" + EscapeMarkdownV2URL(e.URL) + ")"
+	case entity.EntityTextMention:
+		if e.User == nil {
+			return inner
+		}
+		return "[" + inner + "](" + EscapeMarkdownV2URL(mentionURL(e.User.ID)) + ")"
+	case entity.EntityCustomEmoji:
+		return "![" + inner + "](" + EscapeMarkdownV2URL(emojiURL(e.CustomEmojiID)) + ")"
+	}
+
+	return inner
+}
+
+// join puts '\r', which the telegram server ignores, between italic and underline markers
+// that would otherwise run together, like in “___”, which is read as underline first.
+func (markdownV2Writer) join(left, right string) string {
+	if strings.HasPrefix(right, "_") && endsWithMarker(left) {
+		return left + "\r" + right
+	}
+
+	return left + right
+}
+
+// endsWithMarker reports whether s ends with a '_' that isn't escaped.
+func endsWithMarker(s string) bool {
+	if !strings.HasSuffix(s, "_") {
+		return false
+	}
+
+	backslashes := 0
+	for i := len(s) - 2; i >= 0 && s[i] == '\\'; i-- {
+		backslashes++
+	}
+
+	return backslashes%2 == 0
+}
+
+type htmlWriter struct{}
+
+func (htmlWriter) text(s string) string { return EscapeHTML(s) }
+func (htmlWriter) code(s string) string { return EscapeHTML(s) }
+
+func (htmlWriter) join(left, right string) string { return left + right }
+
+func (htmlWriter) wrap(e entity.MessageEntity, inner string) string {
+	switch e.Type {
+	case entity.EntityBold:
+		return "<b>" + inner + "</b>"
+	case entity.EntityItalic:
+		return "<i>" + inner + "</i>"
+	case entity.EntityUnderline:
+		return "<u>" + inner + "</u>"
+	case entity.EntityStrikethrough:
+		return "<s>" + inner + "</s>"
+	case entity.EntitySpoiler:
+		return "<tg-spoiler>" + inner + "</tg-spoiler>"
+	case entity.EntityCode:
+		return "<code>" + inner + "</code>"
+	case entity.EntityPre:
+		if e.Language == "" {
+			return "<pre>" + inner + "</pre>"
+		}
+		return `<pre><code class="language-` + EscapeHTML(e.Language) + `">` + inner + "</code></pre>"
+	case entity.EntityTextLink:
+		return `<a href="` + EscapeHTML(e.URL) + `">` + inner + "</a>"
+	case entity.EntityTextMention:
+		if e.User == nil {
+			return inner
+		}
+		return `<a href="` + mentionURL(e.User.ID) + `">` + inner + "</a>"
+	case entity.EntityCustomEmoji:
+		return `<tg-emoji emoji-id="` + EscapeHTML(e.CustomEmojiID) + `">` + inner + "</tg-emoji>"
+	}
+
+	return inner
+}
+
+// userIDOf returns the id of the user a “tg://user?id=” url links to.
+func userIDOf(url string) (int64, bool) {
+	if !strings.HasPrefix(url, "tg://user?id=") {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(url, "tg://user?id="), 10, 64)
+
+	return id, err == nil
+}
+
+// linkEntity returns the entity of a link to url.
+// Links to users become text mentions and links to custom emoji become custom emoji.
+func linkEntity(url string) entity.MessageEntity {
+	if id, ok := userIDOf(url); ok {
+		return entity.MessageEntity{Type: entity.EntityTextMention, User: &entity.User{ID: id}}
+	}
+	if strings.HasPrefix(url, "tg://emoji?id=") {
+		return entity.MessageEntity{Type: entity.EntityCustomEmoji, CustomEmojiID: strings.TrimPrefix(url, "tg://emoji?id=")}
+	}
+
+	return entity.MessageEntity{Type: entity.EntityTextLink, URL: url}
+}
@@ -0,0 +1,184 @@
+package render
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		entities     []entity.MessageEntity
+		wantMarkdown string
+		wantHTML     string
+	}{
+		{
+			name:         "plain with reserved characters",
+			text:         "1 < 2 & a_b *c* [d](e)",
+			wantMarkdown: `1 < 2 & a\_b \*c\* \[d\]\(e\)`,
+			wantHTML:     "1 &lt; 2 &amp; a_b *c* [d](e)",
+		},
+		{
+			name: "nested after emoji",
+			text: "🎉 bold italic",
+			entities: []entity.MessageEntity{
+				{Type: entity.EntityBold, Offset: 3, Length: 11},
+				{Type: entity.EntityItalic, Offset: 8, Length: 6},
+			},
+			wantMarkdown: "🎉 *bold _italic_*",
+			wantHTML:     "🎉 <b>bold <i>italic</i></b>",
+		},
+		{
+			name: "italic ending with underline",
+			text: "ab",
+			entities: []entity.MessageEntity{
+				{Type: entity.EntityUnderline, Offset: 0, Length: 2},
+				{Type: entity.EntityItalic, Offset: 1, Length: 1},
+			},
+			wantMarkdown: "__a_b_\r__",
+			wantHTML:     "<u>a<i>b</i></u>",
+		},
+		{
+			name: "italic followed by underline",
+			text: "ab",
+			entities: []entity.MessageEntity{
+				{Type: entity.EntityItalic, Offset: 0, Length: 1},
+				{Type: entity.EntityUnderline, Offset: 1, Length: 1},
+			},
+			wantMarkdown: "_a_\r__b__",
+			wantHTML:     "<i>a</i><u>b</u>",
+		},
+		{
+			name: "underline starting with italic",
+			text: "ab",
+			entities: []entity.MessageEntity{
+				{Type: entity.EntityItalic, Offset: 0, Length: 2},
+				{Type: entity.EntityUnderline, Offset: 0, Length: 1},
+			},
+			wantMarkdown: "_\r__a__b_",
+			wantHTML:     "<i><u>a</u>b</i>",
+		},
+		{
+			name: "escaped underscore before underline",
+			text: "a_b",
+			entities: []entity.MessageEntity{
+				{Type: entity.EntityUnderline, Offset: 2, Length: 1},
+			},
+			wantMarkdown: `a\___b__`,
+			wantHTML:     "a_<u>b</u>",
+		},
+		{
+			name: "code and pre",
+			text: "x`y\nfunc()",
+			entities: []entity.MessageEntity{
+				{Type: entity.EntityCode, Offset: 0, Length: 3},
+				{Type: entity.EntityPre, Offset: 4, Length: 6, Language: "go"},
+			},
+			wantMarkdown: "`x\\`y`\n```go\nfunc()```",
+			wantHTML:     "<code>x`y</code>\n<pre><code class=\"language-go\">func()</code></pre>",
+		},
+		{
+			name: "links",
+			text: "site me ⭐",
+			entities: []entity.MessageEntity{
+				{Type: entity.EntityTextLink, Offset: 0, Length: 4, URL: "https://x.y/(a)"},
+				{Type: entity.EntityTextMention, Offset: 5, Length: 2, User: &entity.User{ID: 7}},
+				{Type: entity.EntityCustomEmoji, Offset: 8, Length: 1, CustomEmojiID: "42"},
+			},
+			wantMarkdown: `[site](https://x.y/(a\)) [me](tg://user?id=7) ![⭐](tg://emoji?id=42)`,
+			wantHTML:     `<a href="https://x.y/(a)">site</a> <a href="tg://user?id=7">me</a> <tg-emoji emoji-id="42">⭐</tg-emoji>`,
+		},
+		{
+			name: "spoiler and strikethrough",
+			text: "a b",
+			entities: []entity.MessageEntity{
+				{Type: entity.EntitySpoiler, Offset: 0, Length: 1},
+				{Type: entity.EntityStrikethrough, Offset: 2, Length: 1},
+			},
+			wantMarkdown: "||a|| ~b~",
+			wantHTML:     "<tg-spoiler>a</tg-spoiler> <s>b</s>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markdown := ToMarkdownV2(tt.text, tt.entities)
+			if markdown != tt.wantMarkdown {
+				t.Errorf("ToMarkdownV2() = %q, want %q", markdown, tt.wantMarkdown)
+			}
+			html := ToHTML(tt.text, tt.entities)
+			if html != tt.wantHTML {
+				t.Errorf("ToHTML() = %q, want %q", html, tt.wantHTML)
+			}
+
+			for name, parse := range map[string]func(string) (string, []entity.MessageEntity, error){
+				"FromMarkdownV2": func(string) (string, []entity.MessageEntity, error) { return FromMarkdownV2(markdown) },
+				"FromHTML":       func(string) (string, []entity.MessageEntity, error) { return FromHTML(html) },
+			} {
+				text, entities, err := parse("")
+				if err != nil {
+					t.Fatalf("%s() error = %v", name, err)
+				}
+				if text != tt.text {
+					t.Errorf("%s() text = %q, want %q", name, text, tt.text)
+				}
+				if !reflect.DeepEqual(entities, tt.entities) {
+					t.Errorf("%s() entities = %+v, want %+v", name, entities, tt.entities)
+				}
+			}
+		})
+	}
+}
+
+func TestAutomaticEntitiesArePlainText(t *testing.T) {
+	entities := []entity.MessageEntity{
+		{Type: entity.EntityMention, Offset: 0, Length: 3},
+		{Type: entity.EntityBotCommand, Offset: 4, Length: 6},
+	}
+
+	if got, want := ToMarkdownV2("@me /start", entities), `@me /start`; got != want {
+		t.Errorf("ToMarkdownV2() = %q, want %q", got, want)
+	}
+}
+
+func TestInvalidMarkup(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) (string, []entity.MessageEntity, error)
+		s     string
+	}{
+		{name: "unclosed bold", parse: FromMarkdownV2, s: "*a"},
+		{name: "unclosed code", parse: FromMarkdownV2, s: "`a"},
+		{name: "unclosed pre", parse: FromMarkdownV2, s: "```a"},
+		{name: "link without url", parse: FromMarkdownV2, s: "[a]"},
+		{name: "unclosed url", parse: FromMarkdownV2, s: "[a](b"},
+		{name: "crossing markers", parse: FromMarkdownV2, s: "*a_b*c_"},
+		{name: "unclosed tag", parse: FromHTML, s: "<b>a"},
+		{name: "unterminated tag", parse: FromHTML, s: "<b"},
+		{name: "unexpected closing tag", parse: FromHTML, s: "a</i>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.parse(tt.s); !errors.Is(err, ErrInvalidMarkup) {
+				t.Errorf("error = %v, want ErrInvalidMarkup", err)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	html, err := MarkdownV2ToHTML(`*bold* \_ _it_`)
+	if err != nil || html != "<b>bold</b> _ <i>it</i>" {
+		t.Errorf("MarkdownV2ToHTML() = %q, %v", html, err)
+	}
+
+	markdown, err := HTMLToMarkdownV2("<strong>a</strong> <em>b.</em>")
+	if err != nil || markdown != `*a* _b\._` {
+		t.Errorf("HTMLToMarkdownV2() = %q, %v", markdown, err)
+	}
+}
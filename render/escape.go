@@ -0,0 +1,51 @@
+package render
+
+import "strings"
+
+var (
+	markdownV2Escaper     = newEscaper("_*[]()~`>#+-=|{}.!\\")
+	markdownV2CodeEscaper = newEscaper("`\\")
+	markdownV2URLEscaper  = newEscaper(")\\")
+	markdownEscaper       = newEscaper("_*`[")
+	htmlEscaper           = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+// newEscaper returns a replacer that puts a backslash before every one of chars.
+func newEscaper(chars string) *strings.Replacer {
+	pairs := make([]string, 0, 2*len(chars))
+	for _, c := range chars {
+		pairs = append(pairs, string(c), `\`+string(c))
+	}
+
+	return strings.NewReplacer(pairs...)
+}
+
+// EscapeMarkdownV2 escapes s for the MarkdownV2 parse mode.
+func EscapeMarkdownV2(s string) string {
+	return markdownV2Escaper.Replace(s)
+}
+
+// EscapeMarkdownV2Code escapes s for inline code and code blocks of the MarkdownV2 parse mode.
+func EscapeMarkdownV2Code(s string) string {
+	return markdownV2CodeEscaper.Replace(s)
+}
+
+// EscapeMarkdownV2URL escapes s for the url part of a link of the MarkdownV2 parse mode.
+func EscapeMarkdownV2URL(s string) string {
+	return markdownV2URLEscaper.Replace(s)
+}
+
+// EscapeMarkdown escapes s for the legacy Markdown parse mode.
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// EscapeMarkdownURL escapes s for the url part of a link of the legacy Markdown parse mode.
+func EscapeMarkdownURL(s string) string {
+	return markdownV2URLEscaper.Replace(s)
+}
+
+// EscapeHTML escapes s for the HTML parse mode.
+func EscapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name   string
+		escape func(string) string
+		s      string
+		want   string
+	}{
+		{name: "markdownV2", escape: EscapeMarkdownV2, s: "a_b*c[d](e)~`>#+-=|{}.!\\", want: `a\_b\*c\[d\]\(e\)\~\` + "`" + `\>\#\+\-\=\|\{\}\.\!\\`},
+		{name: "markdownV2 code", escape: EscapeMarkdownV2Code, s: "a_`b`\\", want: "a_\\`b\\`\\\\"},
+		{name: "markdownV2 url", escape: EscapeMarkdownV2URL, s: `https://x.y/a_(b)\`, want: `https://x.y/a_(b\)\\`},
+		{name: "markdown", escape: EscapeMarkdown, s: "a_b*c`d[e]", want: "a\\_b\\*c\\`d\\[e]"},
+		{name: "markdown url", escape: EscapeMarkdownURL, s: "https://x.y/(a)", want: `https://x.y/(a\)`},
+		{name: "html", escape: EscapeHTML, s: `<a href="x">&</a>`, want: "&lt;a href=&quot;x&quot;&gt;&amp;&lt;/a&gt;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.escape(tt.s); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModes(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "markdownV2 italic", got: MarkdownV2.Italic("a_b"), want: `_a\_b_`},
+		{name: "markdownV2 underline", got: MarkdownV2.Underline("a"), want: "__a__"},
+		{name: "markdownV2 link", got: MarkdownV2.Link("a.b", "https://x.y/(a)"), want: `[a\.b](https://x.y/(a\))`},
+		{name: "markdownV2 mention", got: MarkdownV2.Mention("me", 7), want: "[me](tg://user?id=7)"},
+		{name: "markdownV2 join", got: MarkdownV2.Join(MarkdownV2.Italic("a"), MarkdownV2.Underline("b"), MarkdownV2.Italic("c")), want: "_a_\r__b__\r_c_"},
+		{name: "markdownV2 join escaped marker", got: MarkdownV2.Join(MarkdownV2.Escape("a_"), MarkdownV2.Italic("b")), want: `a\__b_`},
+		{name: "markdownV2 pre", got: MarkdownV2.Pre("a`b", "go"), want: "```go\na\\`b```"},
+		{name: "markdown code drops backticks", got: Markdown.Code("a`b`"), want: "`ab`"},
+		{name: "markdown pre drops backticks", got: Markdown.Pre("```x```", "g`o"), want: "```go\nx```"},
+		{name: "markdown link escapes url", got: Markdown.Link("a_b", `https://x.y/(a)\`), want: `[a\_b](https://x.y/(a\)\\)`},
+		{name: "markdown underline", got: Markdown.Underline("a_b"), want: `a\_b`},
+		{name: "markdown custom emoji", got: Markdown.CustomEmoji("*", "1"), want: `\*`},
+		{name: "html join", got: HTML.Join(HTML.Italic("a"), HTML.Underline("b")), want: "<i>a</i><u>b</u>"},
+		{name: "html pre", got: HTML.Pre("a<b", `g"o`), want: `<pre><code class="language-g&quot;o">a&lt;b</code></pre>`},
+		{name: "html custom emoji", got: HTML.CustomEmoji("🙂", "1"), want: `<tg-emoji emoji-id="1">🙂</tg-emoji>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownV2JoinParses(t *testing.T) {
+	text, entities, err := FromMarkdownV2(MarkdownV2.Join(MarkdownV2.Italic("a"), MarkdownV2.Underline("b")))
+	if err != nil {
+		t.Fatalf("FromMarkdownV2() error = %v", err)
+	}
+
+	want := []entity.MessageEntity{
+		{Type: entity.EntityItalic, Offset: 0, Length: 1},
+		{Type: entity.EntityUnderline, Offset: 1, Length: 1},
+	}
+	if text != "ab" || !reflect.DeepEqual(entities, want) {
+		t.Errorf("FromMarkdownV2() = %q, %+v; want %q, %+v", text, entities, "ab", want)
+	}
+}
@@ -0,0 +1,166 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// FromHTML parses HTML formatted text to plain text and its entities.
+// It accepts the tags the telegram server does. Other tags are skipped while their text is kept.
+func FromHTML(s string) (string, []entity.MessageEntity, error) {
+	var o output
+
+	for s != "" {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			o.write(html.UnescapeString(s))
+			break
+		}
+		o.write(html.UnescapeString(s[:i]))
+		s = s[i:]
+
+		end := tagEnd(s)
+		if end < 0 {
+			return "", nil, fmt.Errorf("%w: unterminated tag", ErrInvalidMarkup)
+		}
+		name, attributes, closing := parseTag(s[1:end])
+		s = s[end+1:]
+
+		if closing {
+			if err := closeTag(&o, name); err != nil {
+				return "", nil, err
+			}
+			continue
+		}
+		openTag(&o, name, attributes)
+	}
+
+	return o.result()
+}
+
+// tagEnd returns the index of the '>' ending the tag s starts with, skipping quoted attribute values.
+func tagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '>':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// parseTag parses the inside of a tag to its lower case name and attributes.
+func parseTag(tag string) (name string, attributes map[string]string, closing bool) {
+	tag = strings.TrimSpace(strings.TrimSuffix(tag, "/"))
+	if strings.HasPrefix(tag, "/") {
+		closing = true
+		tag = strings.TrimSpace(tag[1:])
+	}
+
+	i := strings.IndexAny(tag, " \t\r\n")
+	if i < 0 {
+		return strings.ToLower(tag), nil, closing
+	}
+	name, tag = strings.ToLower(tag[:i]), tag[i:]
+
+	attributes = map[string]string{}
+	for {
+		tag = strings.TrimLeft(tag, " \t\r\n")
+		if tag == "" {
+			return name, attributes, closing
+		}
+
+		i = strings.IndexAny(tag, "= \t\r\n")
+		if i < 0 || tag[i] != '=' {
+			if i < 0 {
+				i = len(tag)
+			}
+			attributes[strings.ToLower(tag[:i])] = ""
+			tag = tag[i:]
+			continue
+		}
+
+		key := strings.ToLower(tag[:i])
+		tag = strings.TrimLeft(tag[i+1:], " \t\r\n")
+
+		var value string
+		if tag != "" && (tag[0] == '"' || tag[0] == '\'') {
+			if end := strings.IndexByte(tag[1:], tag[0]); end < 0 {
+				value, tag = tag[1:], ""
+			} else {
+				value, tag = tag[1:end+1], tag[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(tag, " \t\r\n")
+			if end < 0 {
+				end = len(tag)
+			}
+			value, tag = tag[:end], tag[end:]
+		}
+		attributes[key] = html.UnescapeString(value)
+	}
+}
+
+// openTag opens the entity of the tag name. Tags are closed by their own name,
+// so <b> can't be closed with </strong>.
+func openTag(o *output, name string, attributes map[string]string) {
+	switch name {
+	case "b", "strong":
+		o.open(name, entity.MessageEntity{Type: entity.EntityBold})
+	case "i", "em":
+		o.open(name, entity.MessageEntity{Type: entity.EntityItalic})
+	case "u", "ins":
+		o.open(name, entity.MessageEntity{Type: entity.EntityUnderline})
+	case "s", "strike", "del":
+		o.open(name, entity.MessageEntity{Type: entity.EntityStrikethrough})
+	case "tg-spoiler":
+		o.open(name, entity.MessageEntity{Type: entity.EntitySpoiler})
+	case "span":
+		if attributes["class"] == "tg-spoiler" {
+			o.open(name, entity.MessageEntity{Type: entity.EntitySpoiler})
+		} else {
+			o.open(name, entity.MessageEntity{})
+		}
+	case "a":
+		o.open(name, linkEntity(attributes["href"]))
+	case "tg-emoji":
+		o.open(name, entity.MessageEntity{Type: entity.EntityCustomEmoji, CustomEmojiID: attributes["emoji-id"]})
+	case "pre":
+		o.open(name, entity.MessageEntity{Type: entity.EntityPre})
+	case "code":
+		// <pre><code class="language-go"> sets the language of the code block.
+		if o.isOpen("pre") && o.stack[len(o.stack)-1].entity.Offset == o.length {
+			o.stack[len(o.stack)-1].entity.Language = strings.TrimPrefix(attributes["class"], "language-")
+			o.open(name, entity.MessageEntity{})
+		} else {
+			o.open(name, entity.MessageEntity{Type: entity.EntityCode})
+		}
+	}
+}
+
+func closeTag(o *output, name string) error {
+	switch name {
+	case "b", "strong", "i", "em", "u", "ins", "s", "strike", "del", "tg-spoiler", "span", "a", "tg-emoji", "pre", "code":
+		e, err := o.close(name)
+		if err != nil {
+			return err
+		}
+		// entities without a type only keep the tags balanced.
+		if e.Type == "" && e.Length > 0 {
+			o.entities = o.entities[:len(o.entities)-1]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// FromMarkdownV2 parses MarkdownV2 formatted text to plain text and its entities.
+// Reserved characters that aren't escaped are kept as text where they can't be read as formatting,
+// and '\r', which the telegram server ignores, is dropped.
+func FromMarkdownV2(s string) (string, []entity.MessageEntity, error) {
+	var o output
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			next := nextRune(s[i+1:])
+			o.write(next)
+			i += 1 + len(next)
+		case c == '\r':
+			i++
+		case c == '*', c == '~':
+			if err := toggle(&o, s[i:i+1], markerEntity(s[i:i+1])); err != nil {
+				return "", nil, err
+			}
+			i++
+		case strings.HasPrefix(s[i:], "__"), strings.HasPrefix(s[i:], "||"):
+			if err := toggle(&o, s[i:i+2], markerEntity(s[i:i+2])); err != nil {
+				return "", nil, err
+			}
+			i += 2
+		case c == '_':
+			if err := toggle(&o, "_", markerEntity("_")); err != nil {
+				return "", nil, err
+			}
+			i++
+		case strings.HasPrefix(s[i:], "```"):
+			end := strings.Index(s[i+3:], "```")
+			if end < 0 {
+				return "", nil, fmt.Errorf("%w: \"```\" is not closed", ErrInvalidMarkup)
+			}
+			language, code := "", s[i+3:i+3+end]
+			if newline := strings.IndexByte(code, '\n'); newline >= 0 && !strings.ContainsAny(code[:newline], " \t`\\") {
+				language, code = code[:newline], code[newline+1:]
+			}
+			o.open("```", entity.MessageEntity{Type: entity.EntityPre, Language: language})
+			o.write(unescapeCode(code))
+			if _, err := o.close("```"); err != nil {
+				return "", nil, err
+			}
+			i += 3 + end + 3
+		case c == '`':
+			end := codeEnd(s[i+1:], '`')
+			if end < 0 {
+				return "", nil, fmt.Errorf("%w: \"`\" is not closed", ErrInvalidMarkup)
+			}
+			o.open("`", entity.MessageEntity{Type: entity.EntityCode})
+			o.write(unescapeCode(s[i+1 : i+1+end]))
+			if _, err := o.close("`"); err != nil {
+				return "", nil, err
+			}
+			i += 1 + end + 1
+		case c == '[', strings.HasPrefix(s[i:], "!["):
+			i += strings.IndexByte(s[i:], '[') + 1
+			o.open("[", entity.MessageEntity{})
+		case c == ']' && o.isOpen("["):
+			if !strings.HasPrefix(s[i+1:], "(") {
+				return "", nil, fmt.Errorf("%w: link without a url", ErrInvalidMarkup)
+			}
+			end := codeEnd(s[i+2:], ')')
+			if end < 0 {
+				return "", nil, fmt.Errorf("%w: url of a link is not closed", ErrInvalidMarkup)
+			}
+			link := linkEntity(unescapeCode(s[i+2 : i+2+end]))
+			link.Offset = o.stack[len(o.stack)-1].entity.Offset
+			o.stack[len(o.stack)-1].entity = link
+			if _, err := o.close("["); err != nil {
+				return "", nil, err
+			}
+			i += 2 + end + 1
+		default:
+			next := nextRune(s[i:])
+			o.write(next)
+			i += len(next)
+		}
+	}
+
+	return o.result()
+}
+
+// toggle closes the entity marker opened or otherwise opens e.
+func toggle(o *output, marker string, e entity.MessageEntity) error {
+	for _, open := range o.stack {
+		if open.marker == marker {
+			_, err := o.close(marker)
+			return err
+		}
+	}
+	o.open(marker, e)
+
+	return nil
+}
+
+func markerEntity(marker string) entity.MessageEntity {
+	switch marker {
+	case "*":
+		return entity.MessageEntity{Type: entity.EntityBold}
+	case "_":
+		return entity.MessageEntity{Type: entity.EntityItalic}
+	case "__":
+		return entity.MessageEntity{Type: entity.EntityUnderline}
+	case "~":
+		return entity.MessageEntity{Type: entity.EntityStrikethrough}
+	}
+
+	return entity.MessageEntity{Type: entity.EntitySpoiler}
+}
+
+// codeEnd returns the index of the first end in s that isn't escaped.
+func codeEnd(s string, end byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case end:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unescapeCode removes the backslashes escaping characters in code and urls.
+func unescapeCode(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		result.WriteByte(s[i])
+	}
+
+	return result.String()
+}
+
+// nextRune returns the first rune of s as a string.
+func nextRune(s string) string {
+	for i := range s {
+		if i > 0 {
+			return s[:i]
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,140 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// Mode writes formatted text for a parse mode.
+// All the text passed to its methods is escaped, so it can come from users.
+//
+//	text := render.HTML.Join(render.HTML.Bold(name), " ordered ", render.HTML.Code(item))
+//	_, err := bot.SendMessage(entity.MessageEnvelop{ChatID: chatID, Text: text, ParseMode: render.HTML.ParseMode()})
+type Mode interface {
+	// ParseMode returns the value of the parse mode for MessageEnvelop.ParseMode.
+	ParseMode() string
+	// Join concatenates formatted parts.
+	// In MarkdownV2, italic text directly followed by underlined text would run together as “___”,
+	// so Join puts '\r', which the telegram server ignores, between them.
+	Join(parts ...string) string
+	// Escape escapes s without formatting it.
+	Escape(s string) string
+	Bold(s string) string
+	Italic(s string) string
+	// Underline underlines s. The legacy Markdown mode doesn't support it and only escapes s.
+	Underline(s string) string
+	// Strikethrough strikes s through. The legacy Markdown mode doesn't support it and only escapes s.
+	Strikethrough(s string) string
+	// Spoiler hides s as a spoiler. The legacy Markdown mode doesn't support it and only escapes s.
+	Spoiler(s string) string
+	// Code formats s as inline code. The legacy Markdown mode can't escape '`' in code and drops it.
+	Code(s string) string
+	// Pre formats s as a code block of language, which can be empty.
+	// The legacy Markdown mode can't escape '`' in code and drops it.
+	Pre(s, language string) string
+	Link(s, url string) string
+	// Mention links s to the user with the id userID.
+	Mention(s string, userID int64) string
+	// CustomEmoji shows the custom emoji with the id customEmojiID in place of emoji.
+	// The legacy Markdown mode doesn't support it and only escapes emoji.
+	CustomEmoji(emoji, customEmojiID string) string
+}
+
+// Modes of the telegram server.
+var (
+	MarkdownV2 Mode = markdownV2Mode{}
+	Markdown   Mode = markdownMode{}
+	HTML       Mode = htmlMode{}
+)
+
+func mentionURL(userID int64) string {
+	return "tg://user?id=" + strconv.FormatInt(userID, 10)
+}
+
+func emojiURL(customEmojiID string) string {
+	return "tg://emoji?id=" + customEmojiID
+}
+
+type markdownV2Mode struct{}
+
+func (markdownV2Mode) ParseMode() string             { return entity.ParseModeMarkdownV2 }
+func (markdownV2Mode) Escape(s string) string        { return EscapeMarkdownV2(s) }
+func (markdownV2Mode) Bold(s string) string          { return "*" + EscapeMarkdownV2(s) + "*" }
+func (markdownV2Mode) Italic(s string) string        { return "_" + EscapeMarkdownV2(s) + "_" }
+func (markdownV2Mode) Underline(s string) string     { return "__" + EscapeMarkdownV2(s) + "__" }
+func (markdownV2Mode) Strikethrough(s string) string { return "~" + EscapeMarkdownV2(s) + "~" }
+func (markdownV2Mode) Spoiler(s string) string       { return "||" + EscapeMarkdownV2(s) + "||" }
+func (markdownV2Mode) Code(s string) string          { return "`" + EscapeMarkdownV2Code(s) + "`" }
+func (markdownV2Mode) Pre(s, language string) string {
+	return "```" + language + "\n" + EscapeMarkdownV2Code(s) + "```"
+}
+func (markdownV2Mode) Link(s, url string) string {
+	return "[" + EscapeMarkdownV2(s) + "](" + EscapeMarkdownV2URL(url) + ")"
+}
+func (m markdownV2Mode) Mention(s string, id int64) string { return m.Link(s, mentionURL(id)) }
+func (markdownV2Mode) CustomEmoji(emoji, id string) string {
+	return "![" + EscapeMarkdownV2(emoji) + "](" + EscapeMarkdownV2URL(emojiURL(id)) + ")"
+}
+func (markdownV2Mode) Join(parts ...string) string {
+	var w markdownV2Writer
+
+	result := ""
+	for _, part := range parts {
+		result = w.join(result, part)
+	}
+
+	return result
+}
+
+type markdownMode struct{}
+
+func (markdownMode) ParseMode() string             { return entity.ParseModeMarkdown }
+func (markdownMode) Escape(s string) string        { return EscapeMarkdown(s) }
+func (markdownMode) Join(parts ...string) string   { return strings.Join(parts, "") }
+func (markdownMode) Bold(s string) string          { return "*" + EscapeMarkdown(s) + "*" }
+func (markdownMode) Italic(s string) string        { return "_" + EscapeMarkdown(s) + "_" }
+func (markdownMode) Underline(s string) string     { return EscapeMarkdown(s) }
+func (markdownMode) Strikethrough(s string) string { return EscapeMarkdown(s) }
+func (markdownMode) Spoiler(s string) string       { return EscapeMarkdown(s) }
+func (markdownMode) Code(s string) string          { return "`" + stripBackticks(s) + "`" }
+func (markdownMode) Pre(s, language string) string {
+	return "```" + stripBackticks(language) + "\n" + stripBackticks(s) + "```"
+}
+func (markdownMode) Link(s, url string) string {
+	return "[" + EscapeMarkdown(s) + "](" + EscapeMarkdownURL(url) + ")"
+}
+func (m markdownMode) Mention(s string, id int64) string { return m.Link(s, mentionURL(id)) }
+func (markdownMode) CustomEmoji(emoji, _ string) string  { return EscapeMarkdown(emoji) }
+
+// stripBackticks removes the backticks of s, which can't be escaped in legacy Markdown code.
+func stripBackticks(s string) string {
+	return strings.ReplaceAll(s, "`", "")
+}
+
+type htmlMode struct{}
+
+func (htmlMode) ParseMode() string             { return entity.ParseModeHTML }
+func (htmlMode) Escape(s string) string        { return EscapeHTML(s) }
+func (htmlMode) Join(parts ...string) string   { return strings.Join(parts, "") }
+func (htmlMode) Bold(s string) string          { return "<b>" + EscapeHTML(s) + "</b>" }
+func (htmlMode) Italic(s string) string        { return "<i>" + EscapeHTML(s) + "</i>" }
+func (htmlMode) Underline(s string) string     { return "<u>" + EscapeHTML(s) + "</u>" }
+func (htmlMode) Strikethrough(s string) string { return "<s>" + EscapeHTML(s) + "</s>" }
+func (htmlMode) Spoiler(s string) string       { return "<tg-spoiler>" + EscapeHTML(s) + "</tg-spoiler>" }
+func (htmlMode) Code(s string) string          { return "<code>" + EscapeHTML(s) + "</code>" }
+func (htmlMode) Pre(s, language string) string {
+	if language == "" {
+		return "<pre>" + EscapeHTML(s) + "</pre>"
+	}
+
+	return `<pre><code class="language-` + EscapeHTML(language) + `">` + EscapeHTML(s) + "</code></pre>"
+}
+func (htmlMode) Link(s, url string) string {
+	return `<a href="` + EscapeHTML(url) + `">` + EscapeHTML(s) + "</a>"
+}
+func (h htmlMode) Mention(s string, id int64) string { return h.Link(s, mentionURL(id)) }
+func (htmlMode) CustomEmoji(emoji, id string) string {
+	return `<tg-emoji emoji-id="` + EscapeHTML(id) + `">` + EscapeHTML(emoji) + "</tg-emoji>"
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/format"
+)
+
+// ErrInvalidMarkup is returned when formatted text can't be parsed,
+// like when a tag or a marker isn't closed.
+var ErrInvalidMarkup = errors.New("invalid markup")
+
+// opened is a formatting entity that has been opened but not closed yet.
+type opened struct {
+	// marker is the tag or the marker that opened the entity.
+	marker string
+	entity entity.MessageEntity
+}
+
+// output collects the text and the entities of parsed formatted text.
+type output struct {
+	text     strings.Builder
+	length   int64
+	entities []entity.MessageEntity
+	stack    []opened
+}
+
+func (o *output) write(s string) {
+	o.text.WriteString(s)
+	o.length += format.Len(s)
+}
+
+func (o *output) open(marker string, e entity.MessageEntity) {
+	e.Offset = o.length
+	o.stack = append(o.stack, opened{marker: marker, entity: e})
+}
+
+// isOpen reports whether marker opened the innermost entity.
+func (o *output) isOpen(marker string) bool {
+	return len(o.stack) != 0 && o.stack[len(o.stack)-1].marker == marker
+}
+
+// close closes the innermost entity, which must have been opened by marker.
+// Entities without text are dropped.
+func (o *output) close(marker string) (entity.MessageEntity, error) {
+	if !o.isOpen(marker) {
+		return entity.MessageEntity{}, fmt.Errorf("%w: unexpected closing %q", ErrInvalidMarkup, marker)
+	}
+
+	e := o.stack[len(o.stack)-1].entity
+	o.stack = o.stack[:len(o.stack)-1]
+	e.Length = o.length - e.Offset
+	if e.Length > 0 {
+		o.entities = append(o.entities, e)
+	}
+
+	return e, nil
+}
+
+// result returns the parsed text and its entities sorted by offset.
+func (o *output) result() (string, []entity.MessageEntity, error) {
+	if len(o.stack) != 0 {
+		return "", nil, fmt.Errorf("%w: %q is not closed", ErrInvalidMarkup, o.stack[len(o.stack)-1].marker)
+	}
+
+	sort.SliceStable(o.entities, func(i, j int) bool {
+		if o.entities[i].Offset != o.entities[j].Offset {
+			return o.entities[i].Offset < o.entities[j].Offset
+		}
+		return o.entities[i].Length > o.entities[j].Length
+	})
+
+	return o.text.String(), o.entities, nil
+}
@@ -0,0 +1,117 @@
+package gotbot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// DefaultRetryPolicy is a reasonable retry policy for most bots.
+// It is not used unless it is set on BotOptions.RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// RetryPolicy controls how a failed request is repeated.
+//
+// Requests are retried if the telegram server responds with a `retry_after` parameter,
+// with a 5xx error or if the request timed out or its connection was reset.
+// A request whose context is done is never retried.
+// The request body is rebuilt by calling the `getBody` function of SendRawRequest again before every attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for a single request, including the first one.
+	// A value less than 2 disables retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry of a server or network error.
+	// It is doubled for every following attempt.
+	// Zero uses the BaseDelay of DefaultRetryPolicy, so failed requests are never repeated without a pause.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts.
+	// A `retry_after` longer than MaxDelay is not waited for and the error is returned instead.
+	// Zero means no cap.
+	MaxDelay time.Duration
+	// ShouldRetry can be set to override which errors are retried.
+	// It is called with the number of attempts made so far and the error of the last attempt.
+	ShouldRetry func(attempt int, err error) bool
+}
+
+// delay returns how long to wait before the next attempt after the given attempt failed with err.
+// The second return value is false if the request shouldn't be retried.
+func (p *RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if p.ShouldRetry != nil && !p.ShouldRetry(attempt, err) {
+		return 0, false
+	}
+
+	if retryAfter, ok := RetryAfter(err); ok {
+		if p.MaxDelay != 0 && retryAfter > p.MaxDelay {
+			return 0, false
+		}
+
+		return retryAfter, true
+	}
+
+	if p.ShouldRetry == nil && !isTemporary(err) {
+		return 0, false
+	}
+
+	delay := p.BaseDelay
+	if delay <= 0 {
+		delay = DefaultRetryPolicy.BaseDelay
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay != 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay != 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return delay, true
+}
+
+// isTemporary reports whether err is a server or network error that might go away on its own.
+// Network errors are only temporary if they are timeouts or the connection was reset,
+// errors like an invalid url or a failed certificate verification are not.
+func isTemporary(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if apiErr, ok := AsAPIError(err); ok {
+		return apiErr.ErrorCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// a connection closed by the server in the middle of a request surfaces as an unexpected EOF.
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// wait blocks for d or until ctx is done.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+package gotbot
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	serverErr := &APIError{ErrorCode: http.StatusBadGateway, Description: "Bad Gateway"}
+	floodErr := &APIError{ErrorCode: http.StatusTooManyRequests, Description: "Too Many Requests", RetryAfter: 3}
+	policy := &RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		name      string
+		policy    *RetryPolicy
+		attempt   int
+		err       error
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{name: "no policy", policy: nil, attempt: 1, err: serverErr},
+		{name: "first server error", policy: policy, attempt: 1, err: serverErr, wantDelay: 100 * time.Millisecond, wantRetry: true},
+		{name: "doubled", policy: policy, attempt: 3, err: serverErr, wantDelay: 400 * time.Millisecond, wantRetry: true},
+		{name: "capped", policy: &RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: 500 * time.Millisecond}, attempt: 4, err: serverErr, wantDelay: 500 * time.Millisecond, wantRetry: true},
+		{name: "out of attempts", policy: policy, attempt: 5, err: serverErr},
+		{name: "client error", policy: policy, attempt: 1, err: &APIError{ErrorCode: http.StatusBadRequest}},
+		{name: "retry after above max delay", policy: policy, attempt: 1, err: floodErr},
+		{name: "retry after", policy: &RetryPolicy{MaxAttempts: 2}, attempt: 1, err: floodErr, wantDelay: 3 * time.Second, wantRetry: true},
+		{name: "wrapped timeout", policy: policy, attempt: 1, err: fmt.Errorf("send: %w", &netError{timeout: true}), wantDelay: 100 * time.Millisecond, wantRetry: true},
+		{name: "network error", policy: policy, attempt: 1, err: &netError{}},
+		{
+			name:      "connection reset",
+			policy:    policy,
+			attempt:   1,
+			err:       &url.Error{Op: "Post", URL: "https://api.telegram.org", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}},
+			wantDelay: 100 * time.Millisecond,
+			wantRetry: true,
+		},
+		{name: "unexpected eof", policy: policy, attempt: 1, err: &url.Error{Op: "Post", Err: io.ErrUnexpectedEOF}, wantDelay: 100 * time.Millisecond, wantRetry: true},
+		{name: "bad url", policy: policy, attempt: 1, err: &url.Error{Op: "Post", Err: errors.New("unsupported protocol scheme")}},
+		{name: "certificate", policy: policy, attempt: 1, err: &url.Error{Op: "Post", Err: x509.UnknownAuthorityError{}}},
+		{name: "cancelled", policy: policy, attempt: 1, err: &url.Error{Op: "Post", Err: context.Canceled}},
+		{name: "zero base delay", policy: &RetryPolicy{MaxAttempts: 2}, attempt: 1, err: serverErr, wantDelay: DefaultRetryPolicy.BaseDelay, wantRetry: true},
+		{
+			name:      "custom should retry",
+			policy:    &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, ShouldRetry: func(int, error) bool { return true }},
+			attempt:   2,
+			err:       errors.New("anything"),
+			wantDelay: 2 * time.Millisecond,
+			wantRetry: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := tt.policy.delay(tt.attempt, tt.err)
+			if delay != tt.wantDelay || retry != tt.wantRetry {
+				t.Errorf("delay() = %v, %v; want %v, %v", delay, retry, tt.wantDelay, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestRetryCancelledWhileWaiting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = fmt.Fprint(w, `{"ok":false,"error_code":502,"description":"Bad Gateway"}`)
+	}))
+	defer server.Close()
+
+	// the retry is logged right before waiting for it.
+	ctx, cancel := context.WithCancel(context.Background())
+	b := NewBot("token", BotOptions{
+		APIEndpoint: server.URL,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour},
+		Logger:      &cancelLogger{cancel: cancel},
+	})
+
+	_, err := b.SendMessageContext(ctx, entity.MessageEnvelop{ChatID: "1", Text: "hello"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("SendMessageContext() error = %v; want context.Canceled", err)
+	}
+}
+
+// netError is a net.Error that may be a timeout.
+type netError struct {
+	timeout bool
+}
+
+func (*netError) Error() string   { return "network error" }
+func (e *netError) Timeout() bool { return e.timeout }
+func (*netError) Temporary() bool { return true }
+
+// cancelLogger cancels a context when a warning is logged.
+type cancelLogger struct {
+	cancel context.CancelFunc
+}
+
+func (*cancelLogger) Debug(string, Fields)  {}
+func (*cancelLogger) Info(string, Fields)   {}
+func (l *cancelLogger) Warn(string, Fields) { l.cancel() }
+func (*cancelLogger) Error(string, Fields)  {}
+
+func TestRetryNotAfterContextDone(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	b := NewBot("token", BotOptions{
+		APIEndpoint: server.URL,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, ShouldRetry: func(int, error) bool { return true }},
+	})
+
+	if _, err := b.GetMeContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("GetMeContext() error = %v; want context.DeadlineExceeded", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("%d requests sent; want 1", n)
+	}
+}
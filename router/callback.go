@@ -0,0 +1,92 @@
+package router
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CallbackMatcher matches the data of a callback query.
+// It returns the parameters extracted from data, if there are any, and whether data matched.
+type CallbackMatcher func(data string) (map[string]string, bool)
+
+// Exact matches callback data equal to value.
+func Exact(value string) CallbackMatcher {
+	return func(data string) (map[string]string, bool) {
+		return nil, data == value
+	}
+}
+
+// Prefix matches callback data starting with prefix.
+// The rest of the data is available as the “rest” parameter.
+func Prefix(prefix string) CallbackMatcher {
+	return func(data string) (map[string]string, bool) {
+		if !strings.HasPrefix(data, prefix) {
+			return nil, false
+		}
+
+		return map[string]string{"rest": data[len(prefix):]}, true
+	}
+}
+
+// Glob matches callback data against pattern.
+// '*' matches any sequence of characters and '?' matches a single character.
+func Glob(pattern string) CallbackMatcher {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+
+	return Regexp(regexp.MustCompile(expr.String()))
+}
+
+// Regexp matches callback data against re.
+// Named groups of re are available as parameters.
+func Regexp(re *regexp.Regexp) CallbackMatcher {
+	return func(data string) (map[string]string, bool) {
+		match := re.FindStringSubmatch(data)
+		if match == nil {
+			return nil, false
+		}
+
+		params := map[string]string{}
+		for i, name := range re.SubexpNames() {
+			if name != "" {
+				params[name] = match[i]
+			}
+		}
+
+		return params, true
+	}
+}
+
+// patternParam matches a parameter of a Pattern, like “{id}”.
+var patternParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// Pattern matches callback data against pattern in which parameters are enclosed in braces.
+// A parameter matches one or more characters.
+//
+//	Pattern("order:{id}:confirm") // matches "order:42:confirm" with the parameter id = "42"
+func Pattern(pattern string) CallbackMatcher {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	last := 0
+	for _, loc := range patternParam.FindAllStringSubmatchIndex(pattern, -1) {
+		expr.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		expr.WriteString("(?P<" + pattern[loc[2]:loc[3]] + ">.+?)")
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(pattern[last:]))
+	expr.WriteString("$")
+
+	return Regexp(regexp.MustCompile(expr.String()))
+}
@@ -0,0 +1,89 @@
+package router
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/filter"
+)
+
+func TestCallbackMatchers(t *testing.T) {
+	tests := []struct {
+		name       string
+		matcher    CallbackMatcher
+		data       string
+		wantParams map[string]string
+		wantOK     bool
+	}{
+		{name: "exact", matcher: Exact("menu"), data: "menu", wantOK: true},
+		{name: "exact mismatch", matcher: Exact("menu"), data: "menu:1"},
+		{name: "prefix", matcher: Prefix("page:"), data: "page:3", wantParams: map[string]string{"rest": "3"}, wantOK: true},
+		{name: "prefix mismatch", matcher: Prefix("page:"), data: "pages"},
+		{name: "glob", matcher: Glob("item.*:?"), data: "item.a.b:1", wantParams: map[string]string{}, wantOK: true},
+		{name: "glob quotes meta characters", matcher: Glob("item.*"), data: "itemX"},
+		{
+			name:       "regexp",
+			matcher:    Regexp(regexp.MustCompile(`^vote:(?P<choice>yes|no)$`)),
+			data:       "vote:no",
+			wantParams: map[string]string{"choice": "no"},
+			wantOK:     true,
+		},
+		{
+			name:       "pattern",
+			matcher:    Pattern("order:{id}:{action}"),
+			data:       "order:42:confirm:now",
+			wantParams: map[string]string{"id": "42", "action": "confirm:now"},
+			wantOK:     true,
+		},
+		{name: "pattern needs every parameter", matcher: Pattern("order:{id}:confirm"), data: "order::confirm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, ok := tt.matcher(tt.data)
+			if ok != tt.wantOK || (ok && !reflect.DeepEqual(params, tt.wantParams)) {
+				t.Errorf("matcher(%q) = %v, %v; want %v, %v", tt.data, params, ok, tt.wantParams, tt.wantOK)
+			}
+		})
+	}
+}
+
+func callbackUpdate(data string) entity.Update {
+	return entity.Update{CallbackQuery: &entity.CallbackQuery{ID: "q-" + data, Data: data, From: &entity.User{ID: 1}}}
+}
+
+func TestCallbackAnswers(t *testing.T) {
+	api := newFakeAPI()
+	r := New(api)
+
+	r.Callback(Pattern("order:{id}"), func(c *Context) {
+		_ = c.Answer(entity.AnswerCallbackQueryEntity{Text: "order " + c.Param("id")})
+	})
+	r.Callback(Exact("silent"), func(c *Context) {})
+	r.On(func(update entity.Update) bool {
+		return update.CallbackQuery != nil && update.CallbackQuery.Data == "filtered"
+	}, func(c *Context) {
+		if c.CallbackQuery == nil {
+			t.Error("CallbackQuery is not set for a filtered route")
+		}
+	})
+	r.On(filter.All(), func(c *Context) {
+		t.Errorf("catch-all route got callback %q", c.CallbackQuery.Data)
+	}, When(filter.Message()))
+
+	for _, data := range []string{"order:7", "silent", "filtered", "unknown"} {
+		r.HandleUpdate(callbackUpdate(data))
+	}
+
+	want := []entity.AnswerCallbackQueryEntity{
+		{CallbackQueryID: "q-order:7", Text: "order 7"},
+		{CallbackQueryID: "q-silent"},
+		{CallbackQueryID: "q-filtered"},
+		{CallbackQueryID: "q-unknown"},
+	}
+	if !reflect.DeepEqual(api.answered, want) {
+		t.Errorf("answered %+v; want %+v", api.answered, want)
+	}
+}
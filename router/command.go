@@ -0,0 +1,157 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// ErrUnterminatedQuote is returned by SplitArgs when a quoted argument is not closed.
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// Command is a bot command parsed from a message.
+type Command struct {
+	// Name is the command without the leading '/' and the @username suffix.
+	Name string
+	// Mention is the @username suffix of the command without the '@', if there is any.
+	// For example, it is “MyBot” for “/start@MyBot”.
+	Mention string
+	// RawArgs is the text following the command with surrounding spaces removed.
+	RawArgs string
+	// Args is RawArgs split into shell-style arguments.
+	// If RawArgs can't be split, it is split on white space instead.
+	Args []string
+}
+
+// ParseCommand parses the first bot command of the message.
+// The command is looked up in the entities of the text or the caption of the message,
+// so commands sent with a media caption or not at the start of the text are also found.
+// A text or caption starting with '/' is treated as a command even if it has no entities.
+//
+// The second return value is false if the message has no command.
+func ParseCommand(message *entity.Message) (Command, bool) {
+	if message == nil {
+		return Command{}, false
+	}
+
+	text, entities := message.Text, message.Entities
+	if text == "" {
+		text, entities = message.Caption, message.CaptionEntities
+	}
+
+	encoded := utf16.Encode([]rune(text))
+	for _, e := range entities {
+		if e.Type != entity.EntityBotCommand {
+			continue
+		}
+		if e.Offset < 0 || e.Length < 2 || e.Offset+e.Length > int64(len(encoded)) {
+			continue
+		}
+
+		command := string(utf16.Decode(encoded[e.Offset : e.Offset+e.Length]))
+		rest := string(utf16.Decode(encoded[e.Offset+e.Length:]))
+
+		return newCommand(command, rest), true
+	}
+
+	if len(entities) == 0 && strings.HasPrefix(text, "/") {
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end == -1 {
+			end = len(text)
+		}
+		if end > 1 {
+			return newCommand(text[:end], text[end:]), true
+		}
+	}
+
+	return Command{}, false
+}
+
+// newCommand creates a Command from the command text including the leading '/' and the text after it.
+func newCommand(command, rest string) Command {
+	name := strings.TrimPrefix(command, "/")
+	mention := ""
+	if index := strings.Index(name, "@"); index != -1 {
+		name, mention = name[:index], name[index+1:]
+	}
+
+	rawArgs := strings.TrimSpace(rest)
+	args, err := SplitArgs(rawArgs)
+	if err != nil {
+		args = strings.Fields(rawArgs)
+	}
+
+	return Command{
+		Name:    name,
+		Mention: mention,
+		RawArgs: rawArgs,
+		Args:    args,
+	}
+}
+
+// SplitArgs splits s into arguments the way a shell does.
+// Arguments are separated by white space. Single quotes keep everything between them as is,
+// double quotes keep white space and a backslash escapes the character after it
+// outside of single quotes.
+//
+//	SplitArgs(`add "buy milk" 'at 5pm' \"now\"`) // [add, buy milk, at 5pm, "now"]
+//
+// It returns ErrUnterminatedQuote if a quote is not closed.
+func SplitArgs(s string) ([]string, error) {
+	args := []string{}
+
+	var (
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, ErrUnterminatedQuote
+	}
+	if escaped {
+		current.WriteRune('\\')
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
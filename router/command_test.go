@@ -0,0 +1,98 @@
+package router
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *entity.Message
+		want    Command
+		wantOK  bool
+	}{
+		{
+			name:    "no entities",
+			message: &entity.Message{Text: "/start  a b "},
+			want:    Command{Name: "start", RawArgs: "a b", Args: []string{"a", "b"}},
+			wantOK:  true,
+		},
+		{
+			name: "mention",
+			message: &entity.Message{
+				Text:     "/start@MyBot",
+				Entities: []entity.MessageEntity{{Type: entity.EntityBotCommand, Offset: 0, Length: 12}},
+			},
+			want:   Command{Name: "start", Mention: "MyBot", Args: []string{}},
+			wantOK: true,
+		},
+		{
+			name: "after emoji",
+			message: &entity.Message{
+				Text:     "🎉 /remind 5m 'buy milk'",
+				Entities: []entity.MessageEntity{{Type: entity.EntityBotCommand, Offset: 3, Length: 7}},
+			},
+			want:   Command{Name: "remind", RawArgs: "5m 'buy milk'", Args: []string{"5m", "buy milk"}},
+			wantOK: true,
+		},
+		{
+			name: "caption",
+			message: &entity.Message{
+				Caption:         "/save now",
+				CaptionEntities: []entity.MessageEntity{{Type: entity.EntityBotCommand, Offset: 0, Length: 5}},
+			},
+			want:   Command{Name: "save", RawArgs: "now", Args: []string{"now"}},
+			wantOK: true,
+		},
+		{
+			name:    "unterminated quote",
+			message: &entity.Message{Text: `/note "a b`},
+			want:    Command{Name: "note", RawArgs: `"a b`, Args: []string{`"a`, "b"}},
+			wantOK:  true,
+		},
+		{
+			name:    "not a command",
+			message: &entity.Message{Text: "hello /start", Entities: []entity.MessageEntity{{Type: entity.EntityBold, Offset: 0, Length: 5}}},
+		},
+		{name: "slash only", message: &entity.Message{Text: "/ hi"}},
+		{name: "nil", message: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseCommand(tt.message)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCommand() = %#v, %v; want %#v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr error
+	}{
+		{in: "", want: []string{}},
+		{in: "  a   b  ", want: []string{"a", "b"}},
+		{in: `add "buy milk" 'at 5pm' \"now\"`, want: []string{"add", "buy milk", "at 5pm", `"now"`}},
+		{in: `'a \ b' "c \" d"`, want: []string{`a \ b`, `c " d`}},
+		{in: `""`, want: []string{""}},
+		{in: `trailing\`, want: []string{`trailing\`}},
+		{in: `"open`, wantErr: ErrUnterminatedQuote},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := SplitArgs(tt.in)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitArgs(%q) = %q, %v; want %q, %v", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
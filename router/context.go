@@ -0,0 +1,89 @@
+package router
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// ErrNoCallbackQuery is returned by Context.Answer if the update has no callback query.
+var ErrNoCallbackQuery = errors.New("update has no callback query")
+
+// Context holds the update a handler is called with together with the data parsed from it.
+type Context struct {
+	// Update is the update being handled.
+	Update entity.Update
+	// Message is the message of the update if there is any.
+	Message *entity.Message
+	// Command is the command parsed from Message.
+	// It is empty if the handler was not called for a command.
+	Command Command
+	// CallbackQuery is the callback query of the update if there is any.
+	CallbackQuery *entity.CallbackQuery
+	// Params are the parameters extracted from the data of CallbackQuery by the matcher of the handler.
+	Params map[string]string
+
+	api API
+
+	mu       sync.Mutex
+	values   map[any]any
+	answered bool
+}
+
+// Param returns the parameter name extracted from the callback data.
+// It returns an empty string if there is no such parameter.
+func (c *Context) Param(name string) string {
+	return c.Params[name]
+}
+
+// Answer answers the callback query of the context.
+// options.CallbackQueryID is set to the id of the query.
+// Once answered, the query is not answered again automatically when the handler returns.
+func (c *Context) Answer(options entity.AnswerCallbackQueryEntity) error {
+	if c.CallbackQuery == nil {
+		return ErrNoCallbackQuery
+	}
+
+	options.CallbackQueryID = c.CallbackQuery.ID
+	err := c.api.AnswerCallbackQuery(options)
+	if err == nil {
+		c.mu.Lock()
+		c.answered = true
+		c.mu.Unlock()
+	}
+
+	return err
+}
+
+// isAnswered reports whether the callback query was answered.
+func (c *Context) isAnswered() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.answered
+}
+
+// Set stores value under key so that middleware can pass data to the handlers after it.
+// Like the keys of context.WithValue, key must be comparable and packages should use
+// an unexported key type to avoid collisions.
+func (c *Context) Set(key, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.values == nil {
+		c.values = map[any]any{}
+	}
+	c.values[key] = value
+}
+
+// Get returns the value stored under key by Set.
+// The second return value is false if there is no such value.
+func (c *Context) Get(key any) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, ok := c.values[key]
+
+	return value, ok
+}
@@ -1,2 +1,3 @@
-// Package router contains models and methods associated with polling and webhooks
+// Package router contains models and methods associated with polling and webhooks.
+// It routes updates to handlers by their commands.
 package router
@@ -4,6 +4,7 @@ import "github.com/roskee/gotbot/entity"
 
 // Handler is an abstract to hold a certain bot command and its implementation
 type Handler struct {
-	Name     string `json:"name"`
-	Function func(update entity.Update)
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Function    func(update entity.Update)
 }
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// identityRetryInterval is how long Identity waits before asking for the username again after a failure.
+const identityRetryInterval = time.Minute
+
+// MeGetter returns the user of the bot.
+// It is implemented by gotbot.Bot.
+type MeGetter interface {
+	GetMe() (entity.User, error)
+}
+
+// Identity tells whether commands are addressed to the bot.
+// The username of the bot is fetched once and cached. It is safe for concurrent use.
+//
+// gotbot.Bot has an Identity of its own, which is shared by the routers created for it with New.
+type Identity struct {
+	api    MeGetter
+	logger Logger
+
+	mu       sync.RWMutex
+	username string
+	failedAt time.Time
+}
+
+// NewIdentity returns an Identity that fetches the username of the bot from api.
+// Failures to fetch it are logged with logger, which can be nil.
+func NewIdentity(api MeGetter, logger Logger) *Identity {
+	return &Identity{api: api, logger: logger}
+}
+
+// IsMine reports whether command is addressed to this bot.
+// Commands without a @username suffix are addressed to every bot in the chat.
+func (i *Identity) IsMine(command Command) bool {
+	if command.Mention == "" {
+		return true
+	}
+
+	username, ok := i.Username()
+
+	return ok && strings.EqualFold(command.Mention, username)
+}
+
+// Username returns the username of the bot.
+// The second return value is false if it couldn't be fetched,
+// in which case it isn't asked for again for a minute.
+func (i *Identity) Username() (string, bool) {
+	i.mu.RLock()
+	username, failedAt := i.username, i.failedAt
+	i.mu.RUnlock()
+	if username != "" {
+		return username, true
+	}
+	if !failedAt.IsZero() && time.Since(failedAt) < identityRetryInterval {
+		return "", false
+	}
+
+	// the lock isn't held while waiting for the telegram server so that other updates aren't blocked.
+	me, err := i.api.GetMe()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if err == nil && me.UserName == "" {
+		err = errors.New("the bot has no username")
+	}
+	if err != nil {
+		i.failedAt = time.Now()
+		if i.logger != nil {
+			i.logger.Error("failed to get the bot username", map[string]any{
+				"error": err.Error(),
+			})
+		}
+
+		return "", false
+	}
+	i.username = me.UserName
+
+	return me.UserName, true
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+type fakeMe struct {
+	user  entity.User
+	err   error
+	calls int
+}
+
+func (f *fakeMe) GetMe() (entity.User, error) {
+	f.calls++
+
+	return f.user, f.err
+}
+
+func TestIdentityIsMine(t *testing.T) {
+	api := &fakeMe{user: entity.User{UserName: "MyBot"}}
+	identity := NewIdentity(api, nil)
+
+	tests := []struct {
+		mention string
+		want    bool
+	}{
+		{mention: "", want: true},
+		{mention: "MyBot", want: true},
+		{mention: "mybot", want: true},
+		{mention: "OtherBot", want: false},
+	}
+	for _, tt := range tests {
+		if got := identity.IsMine(Command{Name: "start", Mention: tt.mention}); got != tt.want {
+			t.Errorf("IsMine(%q) = %v; want %v", tt.mention, got, tt.want)
+		}
+	}
+
+	if api.calls != 1 {
+		t.Errorf("GetMe was called %d times; want once", api.calls)
+	}
+}
+
+func TestIdentityFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		api  *fakeMe
+	}{
+		{name: "error", api: &fakeMe{err: errors.New("network down")}},
+		{name: "no username", api: &fakeMe{user: entity.User{ID: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &errorLogger{}
+			identity := NewIdentity(tt.api, logger)
+
+			for i := 0; i < 3; i++ {
+				if identity.IsMine(Command{Name: "start", Mention: "MyBot"}) {
+					t.Error("IsMine() = true without a username")
+				}
+			}
+
+			if tt.api.calls != 1 {
+				t.Errorf("GetMe was called %d times after a failure; want once", tt.api.calls)
+			}
+			if len(logger.errors) != 1 {
+				t.Errorf("logged %v; want the failure once", logger.errors)
+			}
+		})
+	}
+}
+
+func TestNewSharesIdentity(t *testing.T) {
+	api := &identityAPI{fakeMe: &fakeMe{user: entity.User{UserName: "MyBot"}}}
+	api.identity = NewIdentity(api, nil)
+
+	if !api.identity.IsMine(Command{Name: "start", Mention: "MyBot"}) {
+		t.Fatal("IsMine() = false for the bot")
+	}
+	if !New(api).IsMine(Command{Name: "start", Mention: "MyBot"}) {
+		t.Error("Router.IsMine() = false for the bot")
+	}
+
+	if api.calls != 1 {
+		t.Errorf("GetMe was called %d times; want once", api.calls)
+	}
+}
+
+// identityAPI is an API that has an Identity like gotbot.Bot.
+type identityAPI struct {
+	*fakeMe
+	identity *Identity
+}
+
+func (a *identityAPI) Identity() *Identity {
+	return a.identity
+}
+
+func (*identityAPI) AnswerCallbackQuery(entity.AnswerCallbackQueryEntity) error {
+	return nil
+}
+
+// errorLogger records the messages of the errors it logs.
+type errorLogger struct {
+	errors []string
+}
+
+func (*errorLogger) Debug(string, map[string]any) {}
+func (*errorLogger) Info(string, map[string]any)  {}
+func (*errorLogger) Warn(string, map[string]any)  {}
+func (l *errorLogger) Error(msg string, _ map[string]any) {
+	l.errors = append(l.errors, msg)
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/roskee/gotbot/filter"
+)
+
+// Middleware wraps a handler to run logic before and after it.
+// A middleware stops the update from reaching the handler by returning without calling next.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Logger is used by the builtin middleware to log.
+// A gotbot.Logger can be used through gotbot.RouterLogger.
+type Logger interface {
+	Debug(msg string, fields map[string]any)
+	Info(msg string, fields map[string]any)
+	Warn(msg string, fields map[string]any)
+	Error(msg string, fields map[string]any)
+}
+
+// chainMiddleware wraps handler with middleware.
+// The first middleware is the outermost one, so it runs first.
+func chainMiddleware(handler HandlerFunc, middleware []Middleware) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
+
+// Recover recovers a panic of the handler and logs it with logger.
+// The update is dropped and other updates keep being handled.
+func Recover(logger Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("handler panicked", map[string]any{
+						"update_id": c.Update.UpdateID,
+						"panic":     fmt.Sprint(r),
+						"stack":     string(debug.Stack()),
+					})
+				}
+			}()
+
+			next(c)
+		}
+	}
+}
+
+// Logging logs every handled update with logger at debug level along with the time it took.
+func Logging(logger Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			start := time.Now()
+			next(c)
+
+			fields := map[string]any{
+				"update_id": c.Update.UpdateID,
+				"duration":  time.Since(start).String(),
+			}
+			if c.Command.Name != "" {
+				fields["command"] = c.Command.Name
+			}
+			if chat := c.Update.GetChat(); chat != nil {
+				fields["chat_id"] = chat.ID
+			}
+			if user := c.Update.GetFrom(); user != nil {
+				fields["user_id"] = user.ID
+			}
+
+			logger.Debug("update handled", fields)
+		}
+	}
+}
+
+// When only lets updates matched by f through.
+// Other updates are dropped.
+func When(f filter.Filter) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if f(c.Update) {
+				next(c)
+			}
+		}
+	}
+}
+
+// AllowUsers only lets updates sent by one of the users with the given ids through.
+// Other updates are dropped.
+func AllowUsers(ids ...int64) Middleware {
+	return When(filter.Users(ids...))
+}
+
+// AllowChats only lets updates of one of the chats with the given ids through.
+// Other updates are dropped.
+func AllowChats(ids ...int64) Middleware {
+	return When(filter.Chats(ids...))
+}
@@ -0,0 +1,127 @@
+package router
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+// fakeAPI is the API of a bot named MyBot.
+type fakeAPI struct {
+	fakeMe
+
+	mu       sync.Mutex
+	answered []entity.AnswerCallbackQueryEntity
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{fakeMe: fakeMe{user: entity.User{UserName: "MyBot"}}}
+}
+
+func (f *fakeAPI) AnswerCallbackQuery(options entity.AnswerCallbackQueryEntity) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.answered = append(f.answered, options)
+
+	return nil
+}
+
+type fakeLogger struct {
+	mu       sync.Mutex
+	messages []string
+	fields   []map[string]any
+}
+
+func (l *fakeLogger) log(msg string, fields map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.messages = append(l.messages, msg)
+	l.fields = append(l.fields, fields)
+}
+
+func (l *fakeLogger) Debug(msg string, fields map[string]any) { l.log(msg, fields) }
+func (l *fakeLogger) Info(msg string, fields map[string]any)  { l.log(msg, fields) }
+func (l *fakeLogger) Warn(msg string, fields map[string]any)  { l.log(msg, fields) }
+func (l *fakeLogger) Error(msg string, fields map[string]any) { l.log(msg, fields) }
+
+func commandUpdate(text string, userID int64) entity.Update {
+	return entity.Update{
+		UpdateID: 1,
+		Message: &entity.Message{
+			Text: text,
+			From: &entity.User{ID: userID},
+			Chat: &entity.Chat{ID: 10},
+		},
+	}
+}
+
+// trace returns a middleware that records name before and after the handler.
+func trace(calls *[]string, name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			*calls = append(*calls, name)
+			next(c)
+			*calls = append(*calls, "/"+name)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+
+	r := New(newFakeAPI())
+	r.Use(trace(&calls, "router"))
+	g := r.Group(trace(&calls, "group"))
+	g.Command("start", func(c *Context) {
+		calls = append(calls, "handler")
+	}, trace(&calls, "route"))
+
+	r.HandleUpdate(commandUpdate("/start", 1))
+
+	want := []string{"router", "group", "route", "handler", "/route", "/group", "/router"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v; want %v", calls, want)
+	}
+}
+
+func TestRecoverAndLogging(t *testing.T) {
+	logger := &fakeLogger{}
+
+	r := New(newFakeAPI())
+	r.Use(Logging(logger), Recover(logger))
+	r.Command("boom", func(c *Context) {
+		panic("boom")
+	})
+
+	r.HandleUpdate(commandUpdate("/boom", 7))
+
+	if want := []string{"handler panicked", "update handled"}; !reflect.DeepEqual(logger.messages, want) {
+		t.Fatalf("logged %v; want %v", logger.messages, want)
+	}
+	fields := logger.fields[1]
+	if fields["command"] != "boom" || fields["chat_id"] != int64(10) || fields["user_id"] != int64(7) {
+		t.Errorf("logged fields %v", fields)
+	}
+}
+
+func TestAllowUsers(t *testing.T) {
+	var handled []int64
+
+	r := New(newFakeAPI())
+	admin := r.Group(AllowUsers(1))
+	admin.Command("ban", func(c *Context) {
+		handled = append(handled, c.Update.GetFrom().ID)
+	})
+
+	r.HandleUpdate(commandUpdate("/ban", 1))
+	r.HandleUpdate(commandUpdate("/ban", 2))
+	r.HandleUpdate(commandUpdate("/ban@OtherBot", 1))
+
+	if want := []int64{1}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("handled updates of %v; want %v", handled, want)
+	}
+}
@@ -0,0 +1,338 @@
+package router
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/filter"
+)
+
+// API is the part of the bot used by the Router.
+// It is implemented by gotbot.Bot.
+type API interface {
+	MeGetter
+	AnswerCallbackQuery(options entity.AnswerCallbackQueryEntity) error
+}
+
+// HandlerFunc handles an update routed to it.
+type HandlerFunc func(c *Context)
+
+// Router routes updates to handlers.
+//
+// Commands addressed to another bot with the @username suffix, like “/start@OtherBot”, are ignored.
+// The username of the bot is fetched with GetMe the first time it is needed.
+//
+// Middleware added to the Router with Use runs for every handler.
+// Handlers can also be grouped to share middleware:
+//
+//	r := router.New(bot)
+//	r.Use(router.Recover(logger))
+//	r.Command("start", start)
+//
+//	admin := r.Group(router.AllowUsers(adminID))
+//	admin.Command("ban", ban)
+//
+// A Router is plugged into a bot through UpdateConfig.OnUpdate:
+//
+//	err := bot.Poll(time.Second, entity.UpdateConfig{OnUpdate: r.HandleUpdate})
+type Router struct {
+	root Group
+
+	api API
+
+	mu        sync.RWMutex
+	commands  map[string]route
+	routes    []filteredRoute
+	callbacks []callbackRoute
+
+	identity *Identity
+}
+
+// New returns a new Router that uses api to validate the @username suffix of commands.
+// If api has an Identity, like gotbot.Bot, the username of the bot is shared with it
+// instead of being fetched again.
+func New(api API) *Router {
+	r := &Router{
+		api:      api,
+		commands: map[string]route{},
+	}
+	if owner, ok := api.(interface{ Identity() *Identity }); ok {
+		r.identity = owner.Identity()
+	} else {
+		r.identity = NewIdentity(api, nil)
+	}
+	r.root = Group{router: r}
+
+	return r
+}
+
+// Use adds middleware that runs for every handler of the router.
+func (r *Router) Use(middleware ...Middleware) {
+	r.root.Use(middleware...)
+}
+
+// Group returns a new group of handlers with the given middleware.
+func (r *Router) Group(middleware ...Middleware) *Group {
+	return r.root.Group(middleware...)
+}
+
+// Command registers handler for the command name. See Group.Command.
+func (r *Router) Command(name string, handler HandlerFunc, middleware ...Middleware) {
+	r.root.Command(name, handler, middleware...)
+}
+
+// Message registers handler for messages that aren't handled by a command. See Group.Message.
+func (r *Router) Message(handler HandlerFunc, middleware ...Middleware) {
+	r.root.Message(handler, middleware...)
+}
+
+// On registers handler for updates matched by f. See Group.On.
+func (r *Router) On(f filter.Filter, handler HandlerFunc, middleware ...Middleware) {
+	r.root.On(f, handler, middleware...)
+}
+
+// Callback registers handler for callback queries with data matched by matcher. See Group.Callback.
+func (r *Router) Callback(matcher CallbackMatcher, handler HandlerFunc, middleware ...Middleware) {
+	r.root.Callback(matcher, handler, middleware...)
+}
+
+// Group is a set of handlers that share middleware.
+// The middleware of a group runs after the middleware of its parent groups
+// and before the middleware of a single handler.
+type Group struct {
+	router     *Router
+	parent     *Group
+	middleware []Middleware
+}
+
+// Use adds middleware to the group.
+// It also applies to the handlers registered on the group before.
+func (g *Group) Use(middleware ...Middleware) {
+	g.router.mu.Lock()
+	defer g.router.mu.Unlock()
+
+	g.middleware = append(g.middleware, middleware...)
+}
+
+// Group returns a new group nested in g with the given middleware.
+func (g *Group) Group(middleware ...Middleware) *Group {
+	return &Group{
+		router:     g.router,
+		parent:     g,
+		middleware: middleware,
+	}
+}
+
+// Command registers handler for the command name.
+// name is the command without the leading '/'. Registering the same name again replaces the handler.
+// middleware only applies to this handler.
+func (g *Group) Command(name string, handler HandlerFunc, middleware ...Middleware) {
+	g.router.mu.Lock()
+	defer g.router.mu.Unlock()
+
+	g.router.commands[strings.TrimPrefix(name, "/")] = route{
+		group:      g,
+		handler:    handler,
+		middleware: middleware,
+	}
+}
+
+// Message registers handler for new messages and channel posts that aren't handled by a command.
+// It is the same as On with filter.Message.
+func (g *Group) Message(handler HandlerFunc, middleware ...Middleware) {
+	g.On(filter.Message(), handler, middleware...)
+}
+
+// On registers handler for updates matched by f that aren't handled by a command or a callback handler.
+// Handlers registered with On and Message are tried in the order they were registered
+// and only the first match is called.
+// middleware only applies to this handler.
+func (g *Group) On(f filter.Filter, handler HandlerFunc, middleware ...Middleware) {
+	g.router.mu.Lock()
+	defer g.router.mu.Unlock()
+
+	g.router.routes = append(g.router.routes, filteredRoute{
+		filter: f,
+		route: route{
+			group:      g,
+			handler:    handler,
+			middleware: middleware,
+		},
+	})
+}
+
+// Callback registers handler for callback queries with data matched by matcher.
+// Callback handlers are tried in the order they were registered and only the first match is called.
+// The parameters extracted by matcher are available on Context.Params.
+//
+// If the query is not answered with Context.Answer by the time the handler returns,
+// it is answered without a notification so the client stops showing a progress bar.
+// middleware only applies to this handler.
+func (g *Group) Callback(matcher CallbackMatcher, handler HandlerFunc, middleware ...Middleware) {
+	g.router.mu.Lock()
+	defer g.router.mu.Unlock()
+
+	g.router.callbacks = append(g.router.callbacks, callbackRoute{
+		matcher: matcher,
+		route: route{
+			group:      g,
+			handler:    handler,
+			middleware: middleware,
+		},
+	})
+}
+
+// filteredRoute is a handler of updates matched by filter registered on a group.
+type filteredRoute struct {
+	filter filter.Filter
+	route
+}
+
+// callbackRoute is a callback query handler registered on a group.
+type callbackRoute struct {
+	matcher CallbackMatcher
+	route
+}
+
+// route is a handler registered on a group.
+type route struct {
+	group      *Group
+	handler    HandlerFunc
+	middleware []Middleware
+}
+
+// build wraps the handler of the route with the middleware of the route and of its groups.
+// It must be called with the lock of the router held.
+func (r route) build() HandlerFunc {
+	handler := chainMiddleware(r.handler, r.middleware)
+	for g := r.group; g != nil; g = g.parent {
+		handler = chainMiddleware(handler, g.middleware)
+	}
+
+	return handler
+}
+
+// HandleUpdate routes update to the matching handler.
+// Updates that don't match any handler are ignored, except for callback queries which are always answered.
+func (r *Router) HandleUpdate(update entity.Update) {
+	c := &Context{
+		Update:  update,
+		Message: update.GetMessage(),
+		api:     r.api,
+	}
+
+	if update.CallbackQuery != nil {
+		c.CallbackQuery = update.CallbackQuery
+
+		handler, params := r.matchCallback(update.CallbackQuery.Data)
+		if handler == nil {
+			// queries without a callback route can still be handled by a filtered route.
+			// They are answered either way so the client stops waiting.
+			handler = r.matchFilter(update)
+		}
+		if handler == nil {
+			handler = func(*Context) {}
+		}
+
+		c.Params = params
+		handleCallback(c, handler)
+		return
+	}
+
+	if message := newMessageOf(update); message != nil {
+		command, ok := ParseCommand(message)
+		if ok && !r.IsMine(command) {
+			return
+		}
+		if ok {
+			if handler := r.matchCommand(command.Name); handler != nil {
+				c.Command = command
+				handler(c)
+				return
+			}
+		}
+	}
+
+	if handler := r.matchFilter(update); handler != nil {
+		handler(c)
+	}
+}
+
+// newMessageOf returns the new message or channel post of update.
+// Commands are only taken from new messages and posts, not from edits.
+func newMessageOf(update entity.Update) *entity.Message {
+	if update.Message != nil {
+		return update.Message
+	}
+
+	return update.ChannelPost
+}
+
+// handleCallback calls handler and answers the callback query if the handler didn't.
+func handleCallback(c *Context, handler HandlerFunc) {
+	// the answer is deferred so that the query is answered even if the handler panics.
+	defer func() {
+		if !c.isAnswered() {
+			// the error is ignored since the client gives up waiting for the answer on its own.
+			_ = c.Answer(entity.AnswerCallbackQueryEntity{})
+		}
+	}()
+
+	handler(c)
+}
+
+// matchCallback returns the handler for callback data together with the parameters extracted from it.
+// The handler is nil if no handler matches.
+func (r *Router) matchCallback(data string) (HandlerFunc, map[string]string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, callback := range r.callbacks {
+		if params, ok := callback.matcher(data); ok {
+			return callback.build(), params
+		}
+	}
+
+	return nil, nil
+}
+
+// matchCommand returns the handler for the command name.
+// The handler is nil if no handler matches.
+func (r *Router) matchCommand(name string) HandlerFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	route, ok := r.commands[name]
+	if !ok {
+		return nil
+	}
+
+	return route.build()
+}
+
+// matchFilter returns the handler of the first route whose filter matches update.
+// The handler is nil if no handler matches.
+func (r *Router) matchFilter(update entity.Update) HandlerFunc {
+	r.mu.RLock()
+	routes := r.routes
+	r.mu.RUnlock()
+
+	// filters are run without the lock held since they might call the telegram server.
+	for _, route := range routes {
+		if route.filter(update) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			return route.build()
+		}
+	}
+
+	return nil
+}
+
+// IsMine reports whether command is addressed to this bot.
+// Commands without a @username suffix are addressed to every bot in the chat.
+func (r *Router) IsMine(command Command) bool {
+	return r.identity.IsMine(command)
+}
@@ -0,0 +1,3 @@
+// Package session keeps data of a chat or a user between updates.
+// A session is loaded before a handler of the router runs and saved after it returns.
+package session
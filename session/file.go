@@ -0,0 +1,110 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileStore is a SessionStore that keeps the sessions in a local JSON file.
+// The file is rewritten on every change, so it suits bots with a moderate number of sessions.
+// A FileStore must be the only user of its file.
+type FileStore struct {
+	path string
+
+	mu    sync.Mutex
+	items map[string]item
+}
+
+// NewFileStore returns a FileStore backed by the file at path.
+// The sessions already in the file are loaded; the file is created on the first change if it doesn't exist.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path:  path,
+		items: map[string]item{},
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) != 0 {
+		if err := json.Unmarshal(content, &s.items); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *FileStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.items[key]
+	if !ok || i.expired(time.Now()) {
+		return nil, false, nil
+	}
+
+	return i.Value, true, nil
+}
+
+func (s *FileStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[key] = newItem(value, ttl)
+
+	return s.flush()
+}
+
+func (s *FileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[key]; !ok {
+		return nil
+	}
+	delete(s.items, key)
+
+	return s.flush()
+}
+
+// flush removes the expired sessions and writes the rest to the file.
+// The file is replaced at once, so it is never left half written.
+func (s *FileStore) flush() error {
+	now := time.Now()
+	for key, i := range s.items {
+		if i.expired(now) {
+			delete(s.items, key)
+		}
+	}
+
+	content, err := json.Marshal(s.items)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
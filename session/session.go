@@ -0,0 +1,177 @@
+package session
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/router"
+)
+
+// contextKey is the key the session of type T is stored under on the router context.
+// It depends on T so that middleware of different session types can be used together.
+type contextKey[T any] struct{}
+
+// KeyFunc returns the key of the session update belongs to.
+// The second return value is false if the update has no session.
+type KeyFunc func(update entity.Update) (string, bool)
+
+// ByChat keeps one session per chat.
+func ByChat(update entity.Update) (string, bool) {
+	chat := update.GetChat()
+	if chat == nil {
+		return "", false
+	}
+
+	return "chat:" + strconv.FormatInt(chat.ID, 10), true
+}
+
+// ByUser keeps one session per user over all chats.
+func ByUser(update entity.Update) (string, bool) {
+	user := update.GetFrom()
+	if user == nil {
+		return "", false
+	}
+
+	return "user:" + strconv.FormatInt(user.ID, 10), true
+}
+
+// ByChatAndUser keeps one session per user in every chat.
+func ByChatAndUser(update entity.Update) (string, bool) {
+	chat, user := update.GetChat(), update.GetFrom()
+	if chat == nil || user == nil {
+		return "", false
+	}
+
+	return "chat:" + strconv.FormatInt(chat.ID, 10) + ":user:" + strconv.FormatInt(user.ID, 10), true
+}
+
+// Options hold the options of the session middleware for sessions of type T.
+type Options[T any] struct {
+	// Store stores the sessions. Defaults to a new MemoryStore.
+	Store SessionStore
+	// Key decides which session an update belongs to. Defaults to ByChatAndUser.
+	Key KeyFunc
+	// TTL is how long a session is kept after it was last used. Zero means forever.
+	TTL time.Duration
+	// New returns a new session for updates that don't have one yet.
+	// Defaults to the zero value of T.
+	New func() T
+	// Logger logs the errors of Store and of encoding sessions. Nothing is logged if it is nil.
+	Logger router.Logger
+}
+
+// state is the session stored on the router context.
+type state[T any] struct {
+	value   T
+	deleted bool
+}
+
+// Middleware returns a router middleware that loads the session of the update before the handler
+// and saves it after the handler returns. Sessions are encoded as JSON.
+// Handlers get the session with Get.
+//
+// If the session can't be loaded from Store, the handler gets a new session which isn't saved,
+// so that the stored session isn't overwritten.
+//
+//	type Cart struct{ Items []string }
+//
+//	r.Use(session.Middleware(session.Options[Cart]{TTL: 24 * time.Hour}))
+//	r.Command("add", func(c *router.Context) {
+//		cart := session.Get[Cart](c)
+//		cart.Items = append(cart.Items, c.Command.RawArgs)
+//	})
+func Middleware[T any](options Options[T]) router.Middleware {
+	if options.Store == nil {
+		options.Store = NewMemoryStore()
+	}
+	if options.Key == nil {
+		options.Key = ByChatAndUser
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) {
+			key, ok := options.Key(c.Update)
+			if !ok {
+				next(c)
+				return
+			}
+
+			s := &state[T]{value: options.newSession()}
+			content, found, err := options.Store.Get(key)
+			if err != nil {
+				options.log("failed to load the session", key, err)
+			} else if found {
+				if err := json.Unmarshal(content, &s.value); err != nil {
+					options.log("failed to decode the session", key, err)
+				}
+			}
+
+			c.Set(contextKey[T]{}, s)
+			next(c)
+
+			if err != nil {
+				return
+			}
+			if s.deleted {
+				err = options.Store.Delete(key)
+			} else if content, err = json.Marshal(s.value); err == nil {
+				err = options.Store.Set(key, content, options.TTL)
+			}
+			if err != nil {
+				options.log("failed to save the session", key, err)
+			}
+		}
+	}
+}
+
+func (o Options[T]) newSession() T {
+	if o.New != nil {
+		return o.New()
+	}
+
+	var value T
+
+	return value
+}
+
+func (o Options[T]) log(msg, key string, err error) {
+	if o.Logger == nil {
+		return
+	}
+
+	o.Logger.Error(msg, map[string]any{
+		"session": key,
+		"error":   err.Error(),
+	})
+}
+
+// Get returns the session of the update of c.
+// Changes to the returned value are saved after the handler returns.
+// It returns nil if the session middleware for T didn't run for c.
+func Get[T any](c *router.Context) *T {
+	value, ok := c.Get(contextKey[T]{})
+	if !ok {
+		return nil
+	}
+
+	s, ok := value.(*state[T])
+	if !ok {
+		return nil
+	}
+
+	return &s.value
+}
+
+// Delete removes the session of the update of c from the store after the handler returns.
+func Delete[T any](c *router.Context) {
+	value, ok := c.Get(contextKey[T]{})
+	if !ok {
+		return
+	}
+
+	if s, ok := value.(*state[T]); ok {
+		s.deleted = true
+	}
+}
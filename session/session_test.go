@@ -0,0 +1,189 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/router"
+)
+
+type fakeAPI struct{}
+
+func (fakeAPI) GetMe() (entity.User, error) {
+	return entity.User{UserName: "MyBot"}, nil
+}
+
+func (fakeAPI) AnswerCallbackQuery(entity.AnswerCallbackQueryEntity) error {
+	return nil
+}
+
+func command(text string) entity.Update {
+	return entity.Update{Message: &entity.Message{
+		Text: text,
+		Chat: &entity.Chat{ID: 10},
+		From: &entity.User{ID: 1},
+	}}
+}
+
+type cart struct{ Items []string }
+
+type profile struct{ Visits int }
+
+func TestMiddlewareOfSeveralTypes(t *testing.T) {
+	store := NewMemoryStore()
+
+	r := router.New(fakeAPI{})
+	r.Use(
+		Middleware(Options[cart]{Store: store, Key: ByChat}),
+		Middleware(Options[profile]{Store: store, Key: ByUser}),
+	)
+	r.Command("add", func(c *router.Context) {
+		items := Get[cart](c)
+		items.Items = append(items.Items, c.Command.RawArgs)
+		Get[profile](c).Visits++
+	})
+	r.Command("clear", func(c *router.Context) {
+		Delete[cart](c)
+	})
+
+	r.HandleUpdate(command("/add milk"))
+	r.HandleUpdate(command("/add bread"))
+
+	if content, _, _ := store.Get("chat:10"); string(content) != `{"Items":["milk","bread"]}` {
+		t.Errorf("stored cart = %s", content)
+	}
+	if content, _, _ := store.Get("user:1"); string(content) != `{"Visits":2}` {
+		t.Errorf("stored profile = %s", content)
+	}
+
+	r.HandleUpdate(command("/clear"))
+	if _, ok, _ := store.Get("chat:10"); ok {
+		t.Error("the cart is still stored after it was deleted")
+	}
+	if _, ok, _ := store.Get("user:1"); !ok {
+		t.Error("deleting the cart deleted the profile")
+	}
+}
+
+func TestMiddlewareWithFailingStore(t *testing.T) {
+	store := &failingStore{}
+
+	var handled bool
+	r := router.New(fakeAPI{})
+	r.Use(Middleware(Options[cart]{Store: store}))
+	r.Command("add", func(c *router.Context) {
+		handled = true
+		if items := Get[cart](c); items == nil || len(items.Items) != 0 {
+			t.Errorf("Get() = %v; want a new session", items)
+		}
+		Get[cart](c).Items = []string{"milk"}
+	})
+
+	r.HandleUpdate(command("/add milk"))
+
+	if !handled {
+		t.Error("the handler didn't run")
+	}
+	if store.saved {
+		t.Error("the session was saved although it couldn't be loaded")
+	}
+}
+
+// failingStore fails to get sessions and records whether one was saved.
+type failingStore struct {
+	saved bool
+}
+
+func (*failingStore) Get(string) ([]byte, bool, error) {
+	return nil, false, errors.New("store unavailable")
+}
+
+func (s *failingStore) Set(string, []byte, time.Duration) error {
+	s.saved = true
+	return nil
+}
+
+func (s *failingStore) Delete(string) error {
+	s.saved = true
+	return nil
+}
+
+func TestGetWithoutMiddleware(t *testing.T) {
+	if got := Get[cart](&router.Context{}); got != nil {
+		t.Errorf("Get() = %v; want nil", got)
+	}
+}
+
+func TestKeyFuncs(t *testing.T) {
+	update := command("/start")
+
+	tests := []struct {
+		name string
+		key  KeyFunc
+		want string
+	}{
+		{name: "chat", key: ByChat, want: "chat:10"},
+		{name: "user", key: ByUser, want: "user:1"},
+		{name: "chat and user", key: ByChatAndUser, want: "chat:10:user:1"},
+	}
+	for _, tt := range tests {
+		if got, ok := tt.key(update); !ok || got != tt.want {
+			t.Errorf("%s: key = %q, %v; want %q", tt.name, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := ByChatAndUser(entity.Update{}); ok {
+		t.Error("ByChatAndUser() of an empty update is ok")
+	}
+}
+
+func TestStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	fileStore, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]SessionStore{"memory": NewMemoryStore(), "file": fileStore}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Set("kept", []byte("1"), 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Set("expired", []byte("2"), time.Nanosecond); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(time.Millisecond)
+
+			if value, ok, err := store.Get("kept"); err != nil || !ok || !reflect.DeepEqual(value, []byte("1")) {
+				t.Errorf("Get(kept) = %s, %v, %v", value, ok, err)
+			}
+			if _, ok, _ := store.Get("expired"); ok {
+				t.Error("Get(expired) found an expired session")
+			}
+
+			if err := store.Delete("kept"); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok, _ := store.Get("kept"); ok {
+				t.Error("Get(kept) found a deleted session")
+			}
+		})
+	}
+
+	// a new store reads the sessions written by the previous one.
+	if err := fileStore.Set("persisted", []byte(`{"a":1}`), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok, _ := reopened.Get("persisted"); !ok || string(value) != `{"a":1}` {
+		t.Errorf("reopened Get(persisted) = %s, %v", value, ok)
+	}
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionStore stores encoded sessions.
+// Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// Get returns the value stored under key.
+	// The second return value is false if there is no such value or it has expired.
+	Get(key string) ([]byte, bool, error)
+	// Set stores value under key. The value expires after ttl; zero means it never expires.
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes the value stored under key, if there is any.
+	Delete(key string) error
+}
+
+// item is a value of a store along with its expiry.
+type item struct {
+	Value []byte `json:"value"`
+	// ExpiresAt is the zero time for values that never expire.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func newItem(value []byte, ttl time.Duration) item {
+	i := item{Value: value}
+	if ttl > 0 {
+		i.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	return i
+}
+
+func (i item) expired(now time.Time) bool {
+	return !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt)
+}
+
+// MemoryStore is a SessionStore that keeps the sessions in memory.
+// The sessions are lost when the program exits.
+type MemoryStore struct {
+	mu        sync.Mutex
+	items     map[string]item
+	cleanedAt time.Time
+}
+
+// NewMemoryStore returns a new empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: map[string]item{},
+	}
+}
+
+func (s *MemoryStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.items[key]
+	if !ok || i.expired(time.Now()) {
+		return nil, false, nil
+	}
+
+	return i.Value, true, nil
+}
+
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanup(time.Now())
+	s.items[key] = newItem(value, ttl)
+
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+
+	return nil
+}
+
+// cleanup removes expired sessions. It runs at most once per minute.
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.cleanedAt) < time.Minute {
+		return
+	}
+	s.cleanedAt = now
+
+	for key, i := range s.items {
+		if i.expired(now) {
+			delete(s.items, key)
+		}
+	}
+}
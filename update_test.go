@@ -0,0 +1,82 @@
+package gotbot
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestExecuteUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   string
+		want     string
+		wantChat int64
+		wantFrom int64
+	}{
+		{name: "message", update: `{"update_id":1,"message":{"message_id":1,"from":{"id":10},"chat":{"id":20},"text":"hi"}}`, want: "OnMessage", wantChat: 20, wantFrom: 10},
+		{name: "edited message", update: `{"update_id":1,"edited_message":{"message_id":1,"from":{"id":10},"chat":{"id":20}}}`, want: "OnEditedMessage", wantChat: 20, wantFrom: 10},
+		{name: "channel post", update: `{"update_id":1,"channel_post":{"message_id":1,"chat":{"id":20}}}`, want: "OnChannelPost", wantChat: 20},
+		{name: "edited channel post", update: `{"update_id":1,"edited_channel_post":{"message_id":1,"chat":{"id":20}}}`, want: "OnEditedChannelPost", wantChat: 20},
+		{name: "inline query", update: `{"update_id":1,"inline_query":{"id":"q","from":{"id":10},"query":"a"}}`, want: "OnInlineQuery", wantFrom: 10},
+		{name: "chosen inline result", update: `{"update_id":1,"chosen_inline_result":{"result_id":"r","from":{"id":10},"query":"a"}}`, want: "OnChosenInlineResult", wantFrom: 10},
+		{name: "callback query", update: `{"update_id":1,"callback_query":{"id":"c","from":{"id":10},"message":{"message_id":1,"chat":{"id":20}},"chat_instance":"i"}}`, want: "OnCallbackQuery", wantChat: 20, wantFrom: 10},
+		{name: "shipping query", update: `{"update_id":1,"shipping_query":{"id":"s","from":{"id":10},"invoice_payload":"p"}}`, want: "OnShippingQuery", wantFrom: 10},
+		{name: "pre checkout query", update: `{"update_id":1,"pre_checkout_query":{"id":"p","from":{"id":10},"currency":"USD","total_amount":100,"invoice_payload":"p"}}`, want: "OnPreCheckoutQuery", wantFrom: 10},
+		{name: "poll", update: `{"update_id":1,"poll":{"id":"p","question":"q","total_voter_count":1}}`, want: "OnPoll"},
+		{name: "poll answer", update: `{"update_id":1,"poll_answer":{"poll_id":"p","user":{"id":10},"option_ids":[0]}}`, want: "OnPollAnswer", wantFrom: 10},
+		{name: "anonymous poll answer", update: `{"update_id":1,"poll_answer":{"poll_id":"p","voter_chat":{"id":20},"option_ids":[0]}}`, want: "OnPollAnswer", wantChat: 20},
+		{name: "my chat member", update: `{"update_id":1,"my_chat_member":{"chat":{"id":20},"from":{"id":10},"date":1}}`, want: "OnMyChatMember", wantChat: 20, wantFrom: 10},
+		{name: "chat member", update: `{"update_id":1,"chat_member":{"chat":{"id":20},"from":{"id":10},"date":1}}`, want: "OnChatMember", wantChat: 20, wantFrom: 10},
+		{name: "chat join request", update: `{"update_id":1,"chat_join_request":{"chat":{"id":20},"from":{"id":10},"user_chat_id":10,"date":1}}`, want: "OnChatJoinRequest", wantChat: 20, wantFrom: 10},
+		{name: "unknown", update: `{"update_id":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update entity.Update
+			if err := json.Unmarshal([]byte(tt.update), &update); err != nil {
+				t.Fatalf("invalid update: %v", err)
+			}
+
+			if chat := update.GetChat(); (chat == nil && tt.wantChat != 0) || (chat != nil && chat.ID != tt.wantChat) {
+				t.Errorf("GetChat() = %+v, want id %d", chat, tt.wantChat)
+			}
+			if from := update.GetFrom(); (from == nil && tt.wantFrom != 0) || (from != nil && from.ID != tt.wantFrom) {
+				t.Errorf("GetFrom() = %+v, want id %d", from, tt.wantFrom)
+			}
+
+			var called []string
+			record := func(name string) { called = append(called, name) }
+			config := entity.UpdateConfig{
+				OnMessage:            func(entity.Message) { record("OnMessage") },
+				OnEditedMessage:      func(entity.Message) { record("OnEditedMessage") },
+				OnChannelPost:        func(entity.Message) { record("OnChannelPost") },
+				OnEditedChannelPost:  func(entity.Message) { record("OnEditedChannelPost") },
+				OnInlineQuery:        func(entity.InlineQuery) { record("OnInlineQuery") },
+				OnChosenInlineResult: func(entity.ChosenInlineResult) { record("OnChosenInlineResult") },
+				OnCallbackQuery:      func(entity.CallbackQuery) { record("OnCallbackQuery") },
+				OnShippingQuery:      func(entity.ShippingQuery) { record("OnShippingQuery") },
+				OnPreCheckoutQuery:   func(entity.PreCheckoutQuery) { record("OnPreCheckoutQuery") },
+				OnPoll:               func(entity.Poll) { record("OnPoll") },
+				OnPollAnswer:         func(entity.PollAnswer) { record("OnPollAnswer") },
+				OnMyChatMember:       func(entity.ChatMemberUpdated) { record("OnMyChatMember") },
+				OnChatMember:         func(entity.ChatMemberUpdated) { record("OnChatMember") },
+				OnChatJoinRequest:    func(entity.ChatJoinRequest) { record("OnChatJoinRequest") },
+			}
+
+			b := NewBot("token", BotOptions{Logger: &panicLogger{}}).(*bot)
+			b.executeUpdate(update, config)
+
+			var want []string
+			if tt.want != "" {
+				want = []string{tt.want}
+			}
+			if !reflect.DeepEqual(called, want) {
+				t.Errorf("called %v, want %v", called, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package gotbot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+	"github.com/roskee/gotbot/envelop"
+)
+
+// secretTokenHeader is the header in which the telegram server sends the secret token of the webhook.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// DefaultWebhookMonitorInterval is the interval of MonitorWebhook if none is given.
+const DefaultWebhookMonitorInterval = time.Minute
+
+// Listen creates a http server to listen for updates as a webhook handler.
+// It returns on failure only
+func (b *bot) Listen(port int, webhook entity.Webhook, config entity.UpdateConfig) error {
+	return b.ListenContext(context.Background(), port, webhook, config)
+}
+
+// ListenContext is the same as Listen but shuts the server down once ctx is done.
+// Running update handlers are given BotOptions.ShutdownTimeout to complete.
+//
+// It returns nil if the server was shut down because ctx is done.
+func (b *bot) ListenContext(ctx context.Context, port int, webhook entity.Webhook, config entity.UpdateConfig) error {
+	_, err := b.SetWebhookContext(ctx, webhook)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr: fmt.Sprintf(":%d", port),
+	}
+
+	return b.serve(ctx, server, server.ListenAndServe, b.WebhookHandler(webhook.SecretToken, config))
+}
+
+// ListenTLS is the same as Listen but serves https using the certificate and key in certFile and keyFile.
+// To use a self-signed certificate, upload it by setting webhook.Certificate.
+//
+// It returns on failure only
+func (b *bot) ListenTLS(port int, certFile, keyFile string, webhook entity.Webhook, config entity.UpdateConfig) error {
+	return b.ListenTLSContext(context.Background(), port, certFile, keyFile, webhook, config)
+}
+
+// ListenTLSContext is the same as ListenTLS but shuts the server down once ctx is done.
+// Running update handlers are given BotOptions.ShutdownTimeout to complete.
+//
+// It returns nil if the server was shut down because ctx is done.
+func (b *bot) ListenTLSContext(ctx context.Context, port int, certFile, keyFile string, webhook entity.Webhook, config entity.UpdateConfig) error {
+	_, err := b.SetWebhookContext(ctx, webhook)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr: fmt.Sprintf(":%d", port),
+	}
+
+	return b.serve(ctx, server, func() error {
+		return server.ListenAndServeTLS(certFile, keyFile)
+	}, b.WebhookHandler(webhook.SecretToken, config))
+}
+
+// serve runs server with handler until it fails or ctx is done.
+// listen is the function that starts server.
+func (b *bot) serve(ctx context.Context, server *http.Server, listen func() error, handler http.Handler) error {
+	server.Handler = handler
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- listen()
+	}()
+
+	b.options.Logger.Info("webhook server started", Fields{
+		"address": server.Addr,
+	})
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	b.options.Logger.Info("shutting down webhook server", Fields{
+		"timeout": b.options.ShutdownTimeout.String(),
+	})
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), b.options.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		b.options.Logger.Error("error while shutting down webhook server", Fields{
+			"error": err.Error(),
+		})
+
+		return err
+	}
+
+	return nil
+}
+
+// WebhookHandler returns a http.Handler that handles updates sent by the telegram server to a webhook.
+// It can be mounted on an existing server. Requests without secretToken in their
+// `X-Telegram-Bot-Api-Secret-Token` header are rejected.
+//
+// The webhook itself has to be registered with SetWebhook.
+func (b *bot) WebhookHandler(secretToken string, config entity.UpdateConfig) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			res.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		// check token
+		token := req.Header.Get(secretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(secretToken), []byte(token)) != 1 {
+			b.options.Logger.Warn("invalid secret token", Fields{
+				"remote_address": req.RemoteAddr,
+			})
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var update entity.Update
+		err = json.Unmarshal(body, &update)
+		if err != nil {
+			b.options.Logger.Warn("invalid update", Fields{
+				"error": err.Error(),
+			})
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		b.executeUpdate(update, config)
+		res.WriteHeader(http.StatusOK)
+	})
+}
+
+// SetWebhook is the implementation of the builtin setWebhook function of the bot.
+// It registers the url to which the telegram server sends updates.
+func (b *bot) SetWebhook(webhook entity.Webhook) (bool, error) {
+	return b.SetWebhookContext(context.Background(), webhook)
+}
+
+// SetWebhookContext is the same as SetWebhook but carries ctx to the request.
+func (b *bot) SetWebhookContext(ctx context.Context, webhook entity.Webhook) (bool, error) {
+	var res []byte
+	var err error
+
+	if webhook.Certificate != nil {
+		// the certificate has to be uploaded as a file
+		res, err = b.SendRawRequestContext(ctx, http.MethodPost, "setWebhook", func() (io.Reader, BodyOptions, error) {
+			return GetMultipartBodyContext(ctx, webhook)
+		}, nil)
+	} else {
+		res, err = b.SendRawRequestContext(ctx, http.MethodPost, "setWebhook", func() (io.Reader, BodyOptions, error) {
+			return GetJSONBody(webhook)
+		}, SetApplicationJSON)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var status bool
+
+	return status, json.Unmarshal(res, &status)
+}
+
+// DeleteWebhook is the implementation of the builtin deleteWebhook function of the bot.
+// It removes the webhook integration so that updates can be received with GetUpdates.
+func (b *bot) DeleteWebhook(options envelop.DeleteWebhookEnvelop) (bool, error) {
+	return b.DeleteWebhookContext(context.Background(), options)
+}
+
+// DeleteWebhookContext is the same as DeleteWebhook but carries ctx to the request.
+func (b *bot) DeleteWebhookContext(ctx context.Context, options envelop.DeleteWebhookEnvelop) (bool, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodPost, "deleteWebhook", func() (io.Reader, BodyOptions, error) {
+		return GetJSONBody(options)
+	}, SetApplicationJSON)
+	if err != nil {
+		return false, err
+	}
+
+	var status bool
+
+	return status, json.Unmarshal(res, &status)
+}
+
+// GetWebhookInfo is the implementation of the builtin getWebhookInfo function of the bot.
+// It returns the current status of the webhook.
+func (b *bot) GetWebhookInfo() (entity.WebhookInfo, error) {
+	return b.GetWebhookInfoContext(context.Background())
+}
+
+// GetWebhookInfoContext is the same as GetWebhookInfo but carries ctx to the request.
+func (b *bot) GetWebhookInfoContext(ctx context.Context) (entity.WebhookInfo, error) {
+	res, err := b.SendRawRequestContext(ctx, http.MethodGet, "getWebhookInfo", nil, nil)
+	if err != nil {
+		return entity.WebhookInfo{}, err
+	}
+
+	var info entity.WebhookInfo
+
+	return info, json.Unmarshal(res, &info)
+}
+
+// MonitorWebhook checks the status of the webhook every interval until ctx is done.
+// An interval that isn't positive is replaced by DefaultWebhookMonitorInterval.
+// It logs a warning when the number of pending updates grows
+// and an error when the telegram server reports a new delivery error.
+func (b *bot) MonitorWebhook(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWebhookMonitorInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// the first check only reports errors, growth is measured from then on
+	var last *entity.WebhookInfo
+	for {
+		info, err := b.GetWebhookInfoContext(ctx)
+		if err != nil && ctx.Err() == nil {
+			b.options.Logger.Error("error while getting webhook info", Fields{
+				"error": err.Error(),
+			})
+		} else if err == nil {
+			if last == nil {
+				last = &entity.WebhookInfo{PendingUpdateCount: info.PendingUpdateCount}
+			}
+			b.checkWebhookInfo(*last, info)
+			last = &info
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkWebhookInfo logs the problems that appeared in info since last.
+func (b *bot) checkWebhookInfo(last, info entity.WebhookInfo) {
+	if info.PendingUpdateCount > last.PendingUpdateCount {
+		b.options.Logger.Warn("webhook pending updates growing", Fields{
+			"url":           info.URL,
+			"pending":       info.PendingUpdateCount,
+			"previous":      last.PendingUpdateCount,
+			"last_error":    info.LastErrorMessage,
+			"last_error_at": info.LastErrorDate,
+		})
+	}
+
+	if info.LastErrorDate > last.LastErrorDate {
+		b.options.Logger.Error("webhook delivery failed", Fields{
+			"url":     info.URL,
+			"error":   info.LastErrorMessage,
+			"date":    time.Unix(info.LastErrorDate, 0).Format(time.RFC3339),
+			"pending": info.PendingUpdateCount,
+		})
+	}
+
+	if info.LastSynchronizationErrorDate > last.LastSynchronizationErrorDate {
+		b.options.Logger.Error("webhook synchronization failed", Fields{
+			"url":  info.URL,
+			"date": time.Unix(info.LastSynchronizationErrorDate, 0).Format(time.RFC3339),
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package gotbot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roskee/gotbot/entity"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	const update = `{"update_id":1,"message":{"message_id":2,"chat":{"id":3},"text":"hello"}}`
+
+	tests := []struct {
+		name        string
+		method      string
+		secretToken string
+		body        string
+		wantStatus  int
+		wantTexts   []string
+	}{
+		{name: "update", method: http.MethodPost, secretToken: "secret", body: update, wantStatus: http.StatusOK, wantTexts: []string{"hello"}},
+		{name: "wrong secret", method: http.MethodPost, secretToken: "wrong", body: update, wantStatus: http.StatusUnauthorized},
+		{name: "missing secret", method: http.MethodPost, body: update, wantStatus: http.StatusUnauthorized},
+		{name: "get", method: http.MethodGet, secretToken: "secret", wantStatus: http.StatusMethodNotAllowed},
+		{name: "bad json", method: http.MethodPost, secretToken: "secret", body: `{"update_id":`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var texts []string
+			handler := NewBot("token", BotOptions{}).WebhookHandler("secret", entity.UpdateConfig{
+				OnMessage: func(message entity.Message) {
+					texts = append(texts, message.Text)
+				},
+			})
+
+			req := httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body))
+			if tt.secretToken != "" {
+				req.Header.Set(secretTokenHeader, tt.secretToken)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(texts, tt.wantTexts) {
+				t.Errorf("OnMessage called with %q, want %q", texts, tt.wantTexts)
+			}
+		})
+	}
+}
+
+func TestMonitorWebhookZeroInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer cancel()
+		_, _ = fmt.Fprint(w, `{"ok":true,"result":{"url":"https://example.com","pending_update_count":0}}`)
+	}))
+	defer server.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewBot("token", BotOptions{APIEndpoint: server.URL}).MonitorWebhook(ctx, 0)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("MonitorWebhook() didn't return after ctx was done")
+	}
+}